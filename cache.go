@@ -0,0 +1,170 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// cacheTTL is how long cached clients and zone metadata are reused.
+const cacheTTL = 15 * time.Minute
+
+// cacheEntry is a cached value with its expiry time.
+type cacheEntry[T any] struct {
+	value   T
+	expires time.Time
+}
+
+// clientCache caches Cloudflare clients and zone metadata across requests,
+// so repeated operations against the same zone do not rebuild clients or
+// re-fetch zone details. All entries are scoped to the API token and are
+// dropped when Cloudflare rejects the token.
+type clientCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	now func() time.Time // for tests; defaults to time.Now
+
+	clients   map[string]cacheEntry[*cloudflare.API] // token + HTTP settings -> client
+	zoneIDs   map[string]cacheEntry[string]          // token + account + zone name -> zone ID
+	zoneNames map[string]cacheEntry[string]          // token + zone ID -> zone name
+}
+
+// client returns a cached client for the config, creating one with create if
+// none is cached or the cached one has expired.
+func (c *clientCache) client(config *TargetConfig, create func() (*cloudflare.API, error)) (*cloudflare.API, error) {
+	key := cacheKey(config.APIToken, hashKey(config.BaseURL, config.CABundle, config.ProxyURL,
+		fmt.Sprint(config.RequestTimeoutSeconds), fmt.Sprint(config.RetryMaxAttempts), fmt.Sprint(config.RetryMaxDelaySeconds)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.clients[key]; ok && c.clock().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = make(map[string]cacheEntry[*cloudflare.API])
+	}
+	c.clients[key] = cacheEntry[*cloudflare.API]{value: client, expires: c.clock().Add(c.entryTTL())}
+
+	return client, nil
+}
+
+// zoneID returns the cached ID of a zone name.
+func (c *clientCache) zoneID(config *TargetConfig, zoneName string) (string, bool) {
+	return c.get(&c.zoneIDs, cacheKey(config.APIToken, config.AccountID, zoneName))
+}
+
+// setZoneID caches the ID of a zone name.
+func (c *clientCache) setZoneID(config *TargetConfig, zoneName, zoneID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.zoneIDs == nil {
+		c.zoneIDs = make(map[string]cacheEntry[string])
+	}
+	c.zoneIDs[cacheKey(config.APIToken, config.AccountID, zoneName)] = cacheEntry[string]{value: zoneID, expires: c.clock().Add(c.entryTTL())}
+}
+
+// zoneName returns the cached name of a zone ID.
+func (c *clientCache) zoneName(config *TargetConfig, zoneID string) (string, bool) {
+	return c.get(&c.zoneNames, cacheKey(config.APIToken, zoneID))
+}
+
+// setZoneName caches the name of a zone ID.
+func (c *clientCache) setZoneName(config *TargetConfig, zoneID, zoneName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.zoneNames == nil {
+		c.zoneNames = make(map[string]cacheEntry[string])
+	}
+	c.zoneNames[cacheKey(config.APIToken, zoneID)] = cacheEntry[string]{value: zoneName, expires: c.clock().Add(c.entryTTL())}
+}
+
+// invalidate drops every entry cached for the config's API token.
+func (c *clientCache) invalidate(config *TargetConfig) {
+	prefix := hashKey(config.APIToken) + "|"
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.clients {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.clients, key)
+		}
+	}
+	for _, entries := range []map[string]cacheEntry[string]{c.zoneIDs, c.zoneNames} {
+		for key := range entries {
+			if strings.HasPrefix(key, prefix) {
+				delete(entries, key)
+			}
+		}
+	}
+}
+
+// invalidateOnAuthError drops the entries cached for the config's API token
+// if err shows Cloudflare rejected the token, e.g. after it was rotated.
+func (c *clientCache) invalidateOnAuthError(config *TargetConfig, err error) {
+	if isAuthError(err) {
+		c.invalidate(config)
+	}
+}
+
+func (c *clientCache) get(entries *map[string]cacheEntry[string], key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := (*entries)[key]
+	if !ok || !c.clock().Before(entry.expires) {
+		return "", false
+	}
+	return entry.value, true
+}
+
+func (c *clientCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *clientCache) entryTTL() time.Duration {
+	if c.ttl > 0 {
+		return c.ttl
+	}
+	return cacheTTL
+}
+
+// cacheKey builds a cache key scoped to an API token. The token itself is
+// hashed so it is not kept around as a map key.
+func cacheKey(token string, parts ...string) string {
+	return hashKey(token) + "|" + strings.Join(parts, "|")
+}
+
+// hashKey returns a short, stable digest of the given strings.
+func hashKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// isAuthError reports whether err is Cloudflare rejecting the API token.
+func isAuthError(err error) bool {
+	var authorizationErr *cloudflare.AuthorizationError
+	var authenticationErr *cloudflare.AuthenticationError
+	return errors.As(err, &authorizationErr) || errors.As(err, &authenticationErr)
+}
@@ -0,0 +1,85 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestClientCache_ReusesClient(t *testing.T) {
+	var cache clientCache
+	config := &TargetConfig{APIToken: "token-a"}
+	created := 0
+	create := func() (*cloudflare.API, error) {
+		created++
+		return cloudflare.NewWithAPIToken(config.APIToken)
+	}
+
+	first, err := cache.client(config, create)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := cache.client(config, create)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first != second {
+		t.Error("expected the cached client to be reused")
+	}
+	if created != 1 {
+		t.Errorf("expected 1 client to be created, got %d", created)
+	}
+
+	// Different HTTP settings need a different client.
+	if _, err := cache.client(&TargetConfig{APIToken: "token-a", BaseURL: "http://localhost:1"}, create); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created != 2 {
+		t.Errorf("expected a new client for different settings, got %d created", created)
+	}
+}
+
+func TestClientCache_Expiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := clientCache{ttl: time.Minute, now: func() time.Time { return now }}
+	config := &TargetConfig{APIToken: "token-a"}
+
+	cache.setZoneName(config, "zone-1", "example.com")
+	if name, ok := cache.zoneName(config, "zone-1"); !ok || name != "example.com" {
+		t.Fatalf("expected cached zone name 'example.com', got %q (%v)", name, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.zoneName(config, "zone-1"); ok {
+		t.Error("expected zone name to expire")
+	}
+}
+
+func TestClientCache_ScopedToToken(t *testing.T) {
+	var cache clientCache
+	tokenA := &TargetConfig{APIToken: "token-a"}
+	tokenB := &TargetConfig{APIToken: "token-b"}
+
+	cache.setZoneID(tokenA, "example.com", "zone-1")
+	if _, ok := cache.zoneID(tokenB, "example.com"); ok {
+		t.Error("expected zone IDs not to be shared between tokens")
+	}
+
+	cache.setZoneID(tokenB, "example.com", "zone-1")
+	cache.invalidateOnAuthError(tokenA, &cloudflare.AuthenticationError{})
+
+	if _, ok := cache.zoneID(tokenA, "example.com"); ok {
+		t.Error("expected token-a entries to be dropped after an auth error")
+	}
+	if _, ok := cache.zoneID(tokenB, "example.com"); !ok {
+		t.Error("expected token-b entries to survive")
+	}
+}
@@ -6,14 +6,19 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/cloudflare/cloudflare-go"
 	"github.com/platform-engineering-labs/formae/pkg/plugin"
 	"github.com/platform-engineering-labs/formae/pkg/plugin/resource"
+	"golang.org/x/time/rate"
 )
 
 // ErrNotImplemented is returned by stub methods that need implementation.
@@ -27,30 +32,93 @@ var ErrNotImplemented = errors.New("not implemented")
 // TargetConfig holds the credentials and configuration for Cloudflare API access.
 type TargetConfig struct {
 	APIToken string `json:"api_token"`
-	ZoneID   string `json:"zone_id"`
+	ZoneID   string `json:"zone_id,omitempty"`
+	ZoneName string `json:"zone_name,omitempty"` // alternative to ZoneID, resolved via the zones API
+
+	// Multi-zone targets: records select one of Zones (names or IDs) via their zone property.
+	AccountID string   `json:"account_id,omitempty"`
+	Zones     []string `json:"zones,omitempty"`
+
+	// Optional HTTP settings, e.g. for egress proxies or local stand-in servers.
+	BaseURL               string `json:"base_url,omitempty"`
+	CABundle              string `json:"ca_bundle,omitempty"` // PEM-encoded certificates
+	ProxyURL              string `json:"proxy_url,omitempty"`
+	RequestTimeoutSeconds int    `json:"request_timeout_seconds,omitempty"`
+
+	// Optional retry settings for 429, 5xx and network errors.
+	RetryMaxAttempts     int `json:"retry_max_attempts,omitempty"`      // total attempts, 1 disables retries
+	RetryMaxDelaySeconds int `json:"retry_max_delay_seconds,omitempty"` // longest single wait, incl. Retry-After
+
+	// Optional per-token request rate ceiling; defaults to Cloudflare's standard limit.
+	MaxRequestsPerSecond float64 `json:"max_requests_per_second,omitempty"`
+
+	// Adopt identical existing records on Create instead of failing; records
+	// may override this.
+	AdoptExisting bool `json:"adopt_existing,omitempty"`
+
+	// Distinguishes the ownership markers of formae installations sharing a
+	// zone; see ownership.go.
+	OwnerID string `json:"owner_id,omitempty"`
+
+	// Optional ownership registry; "txt" keeps companion TXT records like
+	// external-dns, see registry.go.
+	Registry string `json:"registry,omitempty"`
 }
 
 // DNSRecordProperties represents the properties of a DNS record resource.
 type DNSRecordProperties struct {
-	RecordType string  `json:"record_type"`
-	Name       string  `json:"name"`
-	Content    string  `json:"content"`
-	TTL        int     `json:"ttl"`
-	Proxied    bool    `json:"proxied"`
-	Priority   *int    `json:"priority,omitempty"`
-	Comment    *string `json:"comment,omitempty"`
+	RecordType string          `json:"record_type"`
+	Name       string          `json:"name"`
+	Zone       *string         `json:"zone,omitempty"` // zone name or ID; defaults to the target's zone
+	Content    string          `json:"content"`
+	Data       json.RawMessage `json:"data,omitempty"` // structured content, see record_data.go
+	TTL        int             `json:"ttl"`
+	Proxied    bool            `json:"proxied"`
+	Priority   *int            `json:"priority,omitempty"`
+	Comment    *string         `json:"comment,omitempty"`
+	Tags       []DNSRecordTag  `json:"tags,omitempty"`
+	Settings   *RecordSettings `json:"settings,omitempty"` // see records.go
+	Owner      *string         `json:"owner,omitempty"`    // registry owner, reported by Read; not a schema field
+
+	// Write-only options, not reported by Read
+	AdoptExisting *bool `json:"adopt_existing,omitempty"` // overrides the target's adopt_existing
+
+	marker   string // ownership marker appended to the comment, see ownership.go
+	zoneName string // set once the name is relative to its zone, see setCanonicalName
+}
+
+// DNSRecordTag is a Cloudflare record tag. Cloudflare stores tags as
+// "key:value" strings; the value is optional.
+type DNSRecordTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
 }
 
+// dnsRecordResourceType is the formae resource type for Cloudflare DNS records.
+const dnsRecordResourceType = "CLOUDFLARE::DNS::Record"
+
 // Supported record types
 var supportedRecordTypes = map[string]bool{
-	"A":     true,
-	"AAAA":  true,
-	"CNAME": true,
-	"MX":    true,
-	"TXT":   true,
-	"NS":    true,
-	"CAA":   true,
-	"SRV":   true,
+	"A":      true,
+	"AAAA":   true,
+	"CNAME":  true,
+	"MX":     true,
+	"TXT":    true,
+	"NS":     true,
+	"CAA":    true,
+	"SRV":    true,
+	"HTTPS":  true,
+	"SVCB":   true,
+	"TLSA":   true,
+	"SMIMEA": true,
+	"SSHFP":  true,
+	"CERT":   true,
+	"DS":     true,
+	"DNSKEY": true,
+	"PTR":    true,
+	"LOC":    true,
+	"NAPTR":  true,
+	"URI":    true,
 }
 
 // Record types that can be proxied through Cloudflare
@@ -64,6 +132,13 @@ var proxyableRecordTypes = map[string]bool{
 var priorityRequiredTypes = map[string]bool{
 	"MX":  true,
 	"SRV": true,
+	"URI": true,
+}
+
+// Record types whose priority is part of their data instead
+var dataPriorityTypes = map[string]bool{
+	"HTTPS": true,
+	"SVCB":  true,
 }
 
 // =============================================================================
@@ -80,13 +155,76 @@ func parseTargetConfig(configJSON json.RawMessage) (*TargetConfig, error) {
 	if config.APIToken == "" {
 		return nil, fmt.Errorf("api_token is required in target config")
 	}
-	if config.ZoneID == "" {
-		return nil, fmt.Errorf("zone_id is required in target config")
+	if config.isMultiZone() {
+		if config.ZoneID != "" || config.ZoneName != "" {
+			return nil, fmt.Errorf("zone_id and zone_name cannot be combined with zones in target config")
+		}
+		if config.AccountID == "" {
+			return nil, fmt.Errorf("account_id is required in target config when zones is set")
+		}
+		for _, zone := range config.Zones {
+			if strings.TrimSpace(zone) == "" {
+				return nil, fmt.Errorf("zones must not contain empty entries")
+			}
+			if strings.Contains(zone, "/") {
+				return nil, fmt.Errorf("invalid zone %q in zones", zone)
+			}
+		}
+	} else {
+		if config.ZoneID == "" && config.ZoneName == "" {
+			return nil, fmt.Errorf("zone_id, zone_name or zones is required in target config")
+		}
+		if config.ZoneID != "" && config.ZoneName != "" {
+			return nil, fmt.Errorf("only one of zone_id and zone_name may be set in target config")
+		}
+	}
+	if config.BaseURL != "" {
+		if err := validateHTTPURL(config.BaseURL); err != nil {
+			return nil, fmt.Errorf("invalid base_url: %w", err)
+		}
+	}
+	if config.ProxyURL != "" {
+		if err := validateHTTPURL(config.ProxyURL); err != nil {
+			return nil, fmt.Errorf("invalid proxy_url: %w", err)
+		}
+	}
+	if config.CABundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(config.CABundle)) {
+		return nil, fmt.Errorf("ca_bundle contains no valid PEM certificates")
+	}
+	if config.RequestTimeoutSeconds < 0 {
+		return nil, fmt.Errorf("request_timeout_seconds must not be negative")
+	}
+	if config.RetryMaxAttempts < 0 {
+		return nil, fmt.Errorf("retry_max_attempts must not be negative")
+	}
+	if config.RetryMaxDelaySeconds < 0 {
+		return nil, fmt.Errorf("retry_max_delay_seconds must not be negative")
+	}
+	if config.MaxRequestsPerSecond < 0 {
+		return nil, fmt.Errorf("max_requests_per_second must not be negative")
+	}
+	if config.Registry != "" && config.Registry != registryTXT {
+		return nil, fmt.Errorf("registry must be %q if set, got %q", registryTXT, config.Registry)
 	}
 
 	return &config, nil
 }
 
+// validateHTTPURL checks that rawURL is an absolute http or https URL.
+func validateHTTPURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	return nil
+}
+
 // parseProperties parses and validates the DNS record properties JSON.
 func parseProperties(propsJSON json.RawMessage) (*DNSRecordProperties, error) {
 	// Set defaults
@@ -106,8 +244,20 @@ func parseProperties(propsJSON json.RawMessage) (*DNSRecordProperties, error) {
 	if props.Name == "" {
 		return nil, fmt.Errorf("name is required")
 	}
-	if props.Content == "" {
-		return nil, fmt.Errorf("content is required")
+	if props.Content == "" && len(props.Data) == 0 {
+		return nil, fmt.Errorf("content or data is required")
+	}
+
+	// Normalize case, trailing dot and internationalized labels, so that
+	// Unicode and A-label forms of the declared name are the same name from
+	// here on; the name is made relative to its zone once the zone is resolved
+	props.Name = canonicalName(props.Name, "")
+
+	// Zone names are reported lowercase without a trailing dot, so the
+	// declared zone is compared in that form too
+	if props.Zone != nil {
+		zone := normalizeZoneRef(*props.Zone)
+		props.Zone = &zone
 	}
 
 	return props, nil
@@ -120,30 +270,164 @@ func validateProperties(props *DNSRecordProperties) error {
 		return fmt.Errorf("unsupported record type: %s", props.RecordType)
 	}
 
+	// Validate the name, canonical since parseProperties
+	if err := validateName(props.Name); err != nil {
+		return fmt.Errorf("invalid name %q: %w", props.Name, err)
+	}
+	if err := validateRecordName(props.Name); err != nil {
+		return fmt.Errorf("invalid name %q: %w", props.Name, err)
+	}
+
+	// Validate zone reference
+	if props.Zone != nil && (strings.TrimSpace(*props.Zone) == "" || strings.Contains(*props.Zone, "/")) {
+		return fmt.Errorf("invalid zone: %q", *props.Zone)
+	}
+
+	// Validate TTL, priority and content
+	if err := validateTTL(props.TTL); err != nil {
+		return err
+	}
+	if err := validatePriority(props.Priority); err != nil {
+		return err
+	}
+	if err := validateContent(props); err != nil {
+		return err
+	}
+	if err := validateComment(props.Comment); err != nil {
+		return err
+	}
+	if err := validateTags(props.Tags); err != nil {
+		return err
+	}
+
+	// Validate structured data and derive canonical content. This runs before
+	// the priority checks as SRV data may carry the priority.
+	if err := normalizeRecordData(props); err != nil {
+		return err
+	}
+
 	// Validate priority for MX and SRV records
 	if priorityRequiredTypes[props.RecordType] && props.Priority == nil {
 		return fmt.Errorf("priority is required for %s records", props.RecordType)
 	}
 
+	if dataPriorityTypes[props.RecordType] && props.Priority != nil {
+		return fmt.Errorf("priority is part of data for %s records", props.RecordType)
+	}
+
 	// Validate proxied is only set for proxyable types
 	if props.Proxied && !proxyableRecordTypes[props.RecordType] {
 		return fmt.Errorf("proxied can only be set for A, AAAA, and CNAME records")
 	}
 
+	// Validate settings against the record type and proxy status
+	if err := validateSettings(props); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // createCloudflareClient creates a Cloudflare API client from the target config.
-func createCloudflareClient(config *TargetConfig) (*cloudflare.API, error) {
-	return cloudflare.NewWithAPIToken(config.APIToken)
+// Additional client options are applied after the ones derived from the config.
+func createCloudflareClient(config *TargetConfig, limiter *tokenLimiter, opts ...cloudflare.Option) (*cloudflare.API, error) {
+	var configOpts []cloudflare.Option
+
+	if config.BaseURL != "" {
+		configOpts = append(configOpts, cloudflare.BaseURL(strings.TrimSuffix(config.BaseURL, "/")))
+	}
+
+	httpClient, err := createHTTPClient(config, limiter)
+	if err != nil {
+		return nil, err
+	}
+	// Retries and rate limiting are handled by the HTTP client's transport,
+	// which honors Retry-After, the rate-limit headers and the request deadline.
+	configOpts = append(configOpts,
+		cloudflare.HTTPClient(httpClient),
+		cloudflare.UsingRetryPolicy(0, 0, 0),
+		cloudflare.UsingRateLimit(float64(rate.Inf)),
+	)
+
+	return cloudflare.NewWithAPIToken(config.APIToken, append(configOpts, opts...)...)
+}
+
+// createHTTPClient builds an HTTP client honoring the CA bundle, proxy,
+// timeout and retry settings of the target config. Every request waits for
+// the given limiter.
+func createHTTPClient(config *TargetConfig, limiter *tokenLimiter) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(config.CABundle)) {
+			return nil, fmt.Errorf("ca_bundle contains no valid PEM certificates")
+		}
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    pool,
+		}
+	}
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy_url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	limited := &rateLimitTransport{next: transport, limiter: limiter}
+	return &http.Client{Transport: newRetryTransport(limited, config)}, nil
+}
+
+// apiContent returns the record content as sent to Cloudflare.
+func apiContent(props *DNSRecordProperties) string {
+	if props.RecordType == "TXT" {
+		return txtContent(props.Content)
+	}
+	return props.Content
+}
+
+// cloudflareTags converts record tags to Cloudflare's "key:value" form. An
+// empty list is sent on update so that removed tags are cleared.
+func cloudflareTags(tags []DNSRecordTag) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag.Value == "" {
+			result = append(result, tag.Key)
+			continue
+		}
+		result = append(result, tag.Key+":"+tag.Value)
+	}
+	return result
+}
+
+// recordTags converts Cloudflare's "key:value" tags to record tags, sorted by
+// key.
+func recordTags(tags []string) []DNSRecordTag {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := make([]DNSRecordTag, 0, len(tags))
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, ":")
+		result = append(result, DNSRecordTag{Key: key, Value: value})
+	}
+	sortTags(result)
+	return result
 }
 
 // propsToCreateParams converts DNSRecordProperties to Cloudflare CreateDNSRecordParams.
-func propsToCreateParams(props *DNSRecordProperties) cloudflare.CreateDNSRecordParams {
+func propsToCreateParams(props *DNSRecordProperties, zoneName string) cloudflare.CreateDNSRecordParams {
 	params := cloudflare.CreateDNSRecordParams{
 		Type:    props.RecordType,
-		Name:    props.Name,
-		Content: props.Content,
+		Name:    recordFQDN(props.Name, zoneName),
+		Content: apiContent(props),
+		Data:    cloudflareRecordData(props),
 		TTL:     props.TTL,
 		Proxied: &props.Proxied,
 	}
@@ -153,23 +437,27 @@ func propsToCreateParams(props *DNSRecordProperties) cloudflare.CreateDNSRecordP
 		params.Priority = &priority
 	}
 
-	if props.Comment != nil {
-		params.Comment = *props.Comment
+	if comment := apiComment(props); comment != nil {
+		params.Comment = *comment
 	}
 
+	params.Tags = cloudflareTags(props.Tags)
+
 	return params
 }
 
 // propsToUpdateParams converts DNSRecordProperties to Cloudflare UpdateDNSRecordParams.
-func propsToUpdateParams(props *DNSRecordProperties, recordID string) cloudflare.UpdateDNSRecordParams {
+func propsToUpdateParams(props *DNSRecordProperties, recordID, zoneName string) cloudflare.UpdateDNSRecordParams {
 	params := cloudflare.UpdateDNSRecordParams{
 		ID:      recordID,
 		Type:    props.RecordType,
-		Name:    props.Name,
-		Content: props.Content,
+		Name:    recordFQDN(props.Name, zoneName),
+		Content: apiContent(props),
+		Data:    cloudflareRecordData(props),
 		TTL:     props.TTL,
 		Proxied: &props.Proxied,
-		Comment: props.Comment,
+		Comment: apiComment(props),
+		Tags:    cloudflareTags(props.Tags),
 	}
 
 	if props.Priority != nil {
@@ -183,20 +471,11 @@ func propsToUpdateParams(props *DNSRecordProperties, recordID string) cloudflare
 // recordToProperties converts a Cloudflare DNSRecord to DNSRecordProperties.
 // zoneName is used to strip the zone suffix from the FQDN returned by Cloudflare.
 func recordToProperties(record cloudflare.DNSRecord, zoneName string) *DNSRecordProperties {
-	// Cloudflare returns the FQDN (e.g., "www.example.com"), but we store the short name ("www")
-	name := record.Name
-	if zoneName != "" {
-		suffix := "." + zoneName
-		name = strings.TrimSuffix(name, suffix)
-		// Handle zone apex - Cloudflare returns the zone name itself
-		if name == zoneName {
-			name = "@"
-		}
-	}
-
+	// Cloudflare returns the FQDN (e.g., "www.example.com"), but we store the
+	// canonical short name ("www", or "@" for the zone apex)
 	props := &DNSRecordProperties{
 		RecordType: record.Type,
-		Name:       name,
+		Name:       canonicalName(record.Name, zoneName),
 		Content:    record.Content,
 		TTL:        record.TTL,
 	}
@@ -205,25 +484,105 @@ func recordToProperties(record cloudflare.DNSRecord, zoneName string) *DNSRecord
 		props.Proxied = *record.Proxied
 	}
 
-	if record.Priority != nil {
+	if record.Priority != nil && !dataPriorityTypes[record.Type] {
 		priority := int(*record.Priority)
 		props.Priority = &priority
 	}
 
-	if record.Comment != "" {
-		props.Comment = &record.Comment
+	// Report hostnames in Unicode without a trailing dot and TXT content as
+	// its plain value, as they are declared
+	switch {
+	case hostnameRecordTypes[record.Type] && props.Content != ".":
+		props.Content = unicodeName(strings.TrimSuffix(props.Content, "."))
+	case record.Type == "TXT":
+		props.Content = txtValue(props.Content)
 	}
 
+	// Report structured content in canonical form
+	if data := recordDataFromProperties(props); data != nil {
+		props.Content = data.content()
+		props.Data, _ = json.Marshal(data)
+	}
+
+	// Report the comment without the ownership marker
+	if comment, _ := splitComment(record.Comment); comment != "" {
+		props.Comment = &comment
+	}
+
+	props.Tags = recordTags(record.Tags)
+
 	return props
 }
 
-// getZoneName fetches the zone name from Cloudflare using the zone ID.
-func getZoneName(ctx context.Context, client *cloudflare.API, zoneID string) (string, error) {
-	zone, err := client.ZoneDetails(ctx, zoneID)
+// parsePageToken parses a List page token of the form "<page>" or
+// "<zone index>:<page>". Invalid tokens start from the first page.
+func parsePageToken(token *string) (zoneIndex, page int) {
+	page = 1
+	if token == nil || *token == "" {
+		return 0, page
+	}
+	if strings.Contains(*token, ":") {
+		if _, err := fmt.Sscanf(*token, "%d:%d", &zoneIndex, &page); err != nil || zoneIndex < 0 || page < 1 {
+			return 0, 1
+		}
+		return zoneIndex, page
+	}
+	if _, err := fmt.Sscanf(*token, "%d", &page); err != nil || page < 1 {
+		return 0, 1
+	}
+	return 0, page
+}
+
+// formatPageToken formats a List page token. Single-zone targets keep the
+// plain page number.
+func formatPageToken(config *TargetConfig, zoneIndex, page int) string {
+	if config.isMultiZone() {
+		return fmt.Sprintf("%d:%d", zoneIndex, page)
+	}
+	return fmt.Sprintf("%d", page)
+}
+
+// isManageable reports whether the plugin can round-trip a record found in a
+// zone: its type must be supported, structured content must parse and
+// Cloudflare must allow changing it.
+func isManageable(record cloudflare.DNSRecord) bool {
+	if !supportedRecordTypes[record.Type] || isCompanionRecord(record) {
+		return false
+	}
+	if _, structured := recordDataTypes[record.Type]; structured {
+		props := &DNSRecordProperties{RecordType: record.Type, Name: record.Name, Content: record.Content}
+		if recordDataFromProperties(props) == nil {
+			return false
+		}
+	}
+	meta, _ := record.Meta.(map[string]interface{})
+	readOnly, _ := meta["read_only"].(bool)
+	return !readOnly
+}
+
+// zoneRecordToProperties converts a record of a resolved zone, including the
+// zone reference of its native ID, the record's settings and its registry
+// owner.
+func zoneRecordToProperties(record dnsRecord, zone *recordZone, zoneName string, entry *registryEntry) *DNSRecordProperties {
+	props := recordToProperties(record.DNSRecord, zoneName)
+	props.Owner = entry.ownerRef()
+	if record.Settings != (RecordSettings{}) {
+		props.Settings = &record.Settings
+	}
+	if zone.Ref != "" {
+		props.Zone = &zone.Ref
+	}
+	return props
+}
+
+// writtenProperties returns the properties of a created or updated record as
+// Read reports them, so the state formae stores matches later reads.
+func writtenProperties(record dnsRecord, zone *recordZone, zoneName string, entry *registryEntry) json.RawMessage {
+	propsJSON, err := json.Marshal(zoneRecordToProperties(record, zone, zoneName, entry))
 	if err != nil {
-		return "", fmt.Errorf("failed to get zone details: %w", err)
+		return nil
 	}
-	return zone.Name, nil
+	return propsJSON
 }
 
 // propertiesToJSON converts DNSRecordProperties to a JSON string.
@@ -235,52 +594,58 @@ func propertiesToJSON(props *DNSRecordProperties) (string, error) {
 	return string(bytes), nil
 }
 
-// isNotFoundError checks if the error is a "not found" error from Cloudflare.
-func isNotFoundError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "not found") ||
-		strings.Contains(errStr, "does not exist") ||
-		strings.Contains(errStr, "404")
-}
-
 // Plugin implements the Formae ResourcePlugin interface.
 // The SDK automatically provides identity methods (Name, Version, Namespace)
 // by reading formae-plugin.pkl at startup.
-type Plugin struct{}
+type Plugin struct {
+	// clientOptions are passed to every Cloudflare client the plugin creates.
+	// Unit tests use this to point the plugin at an in-process fake API.
+	clientOptions []cloudflare.Option
+
+	cache    clientCache
+	limiters rateLimiters
+}
 
 // Compile-time check: Plugin must satisfy ResourcePlugin interface.
 var _ plugin.ResourcePlugin = &Plugin{}
 
+// client returns a Cloudflare client for the target config, reusing a cached
+// client where possible.
+func (p *Plugin) client(config *TargetConfig) (*cloudflare.API, error) {
+	limiter := p.limiters.get(config)
+	return p.cache.client(config, func() (*cloudflare.API, error) {
+		return createCloudflareClient(config, limiter, p.clientOptions...)
+	})
+}
+
 // =============================================================================
 // Configuration Methods
 // =============================================================================
 
 // RateLimit returns the rate limiting configuration for this plugin.
-// Cloudflare API limit: 1200 requests per 5 minutes = 4 requests per second.
+// This is only an upper bound across all targets: each API token gets its own
+// token bucket (4 requests per second by default, matching Cloudflare's limit
+// of 1200 requests per 5 minutes), which adapts to Cloudflare's rate-limit
+// headers. See ratelimit.go.
 func (p *Plugin) RateLimit() plugin.RateLimitConfig {
 	return plugin.RateLimitConfig{
 		Scope:                            plugin.RateLimitScopeNamespace,
-		MaxRequestsPerSecondForNamespace: 4,
+		MaxRequestsPerSecondForNamespace: namespaceRequestsPerSecond,
 	}
 }
 
 // DiscoveryFilters returns filters to exclude certain resources from discovery.
 // Resources matching ALL conditions in a filter are excluded.
-// Return nil if you want to discover all resources.
+// Records tagged "skip-discovery:true" are not discovered.
 func (p *Plugin) DiscoveryFilters() []plugin.MatchFilter {
-	// Example: exclude resources with a specific tag
-	// return []plugin.MatchFilter{
-	//     {
-	//         ResourceTypes: []string{"CLOUDFLARE::Service::Resource"},
-	//         Conditions: []plugin.FilterCondition{
-	//             {PropertyPath: "$.Tags[?(@.Key=='skip-discovery')].Value", PropertyValue: "true"},
-	//         },
-	//     },
-	// }
-	return nil
+	return []plugin.MatchFilter{
+		{
+			ResourceTypes: []string{dnsRecordResourceType},
+			Conditions: []plugin.FilterCondition{
+				{PropertyPath: "$.tags[?(@.key=='skip-discovery')].value", PropertyValue: "true"},
+			},
+		},
+	}
 }
 
 // LabelConfig returns the configuration for extracting human-readable labels
@@ -340,7 +705,7 @@ func (p *Plugin) Create(ctx context.Context, req *resource.CreateRequest) (*reso
 	}
 
 	// Create Cloudflare client
-	client, err := createCloudflareClient(config)
+	client, err := p.client(config)
 	if err != nil {
 		return &resource.CreateResult{
 			ProgressResult: &resource.ProgressResult{
@@ -352,15 +717,75 @@ func (p *Plugin) Create(ctx context.Context, req *resource.CreateRequest) (*reso
 		}, nil
 	}
 
-	// Create the DNS record
-	rc := cloudflare.ZoneIdentifier(config.ZoneID)
-	record, err := client.CreateDNSRecord(ctx, rc, propsToCreateParams(props))
+	// Resolve the zone the record lives in
+	zoneRef := ""
+	if props.Zone != nil {
+		zoneRef = *props.Zone
+	}
+	zone, err := p.resolveRecordZone(ctx, client, config, zoneRef)
 	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
 		return &resource.CreateResult{
 			ProgressResult: &resource.ProgressResult{
 				Operation:       resource.OperationCreate,
 				OperationStatus: resource.OperationStatusFailure,
-				ErrorCode:       resource.OperationErrorCodeInternalFailure,
+				ErrorCode:       errorCode(err),
+				StatusMessage:   fmt.Sprintf("Failed to resolve zone: %v", err),
+			},
+		}, nil
+	}
+
+	// Make the name relative to the zone
+	zoneName, err := p.resolveZoneName(ctx, client, config, zone)
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return &resource.CreateResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationCreate,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       errorCode(err),
+				StatusMessage:   fmt.Sprintf("Failed to get zone name for zone %s: %v", zone.ID, err),
+			},
+		}, nil
+	}
+	if err := setCanonicalName(props, zoneName); err != nil {
+		return &resource.CreateResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationCreate,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       resource.OperationErrorCodeInvalidRequest,
+				StatusMessage:   fmt.Sprintf("Invalid properties: %v", err),
+			},
+		}, nil
+	}
+
+	// Create the DNS record, or return the one an earlier attempt created or
+	// an identical one to adopt, unless it conflicts with records in the zone
+	// or someone else owns the name
+	var record dnsRecord
+	var entry *registryEntry
+	props.marker = ownershipMarker(config, req.Label, props, zoneName)
+	reg := newRegistry(config, client, zone.ID, zoneName)
+	atName, err := listRecordsAt(ctx, client, zone.ID, props.Name, zoneName)
+	if err == nil {
+		err = checkConflicts(ctx, client, zone.ID, props, zoneName, "", atName)
+	}
+	if err == nil {
+		entry, err = reg.check(ctx, props.RecordType, props.Name)
+	}
+	if err == nil {
+		record, err = createOrAdoptDNSRecord(ctx, client, zone.ID, props, zoneName, adoptExisting(config, props), atName)
+	}
+	if err == nil {
+		err = reg.claim(ctx, entry)
+	}
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return &resource.CreateResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationCreate,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       errorCode(err),
 				StatusMessage:   fmt.Sprintf("Failed to create DNS record: %v", err),
 			},
 		}, nil
@@ -368,9 +793,10 @@ func (p *Plugin) Create(ctx context.Context, req *resource.CreateRequest) (*reso
 
 	return &resource.CreateResult{
 		ProgressResult: &resource.ProgressResult{
-			Operation:       resource.OperationCreate,
-			OperationStatus: resource.OperationStatusSuccess,
-			NativeID:        record.ID,
+			Operation:          resource.OperationCreate,
+			OperationStatus:    resource.OperationStatusSuccess,
+			NativeID:           joinNativeID(zone.Ref, record.ID),
+			ResourceProperties: writtenProperties(record, zone, zoneName, entry),
 		},
 	}, nil
 }
@@ -380,55 +806,49 @@ func (p *Plugin) Read(ctx context.Context, req *resource.ReadRequest) (*resource
 	// Parse target config
 	config, err := parseTargetConfig(req.TargetConfig)
 	if err != nil {
-		return &resource.ReadResult{
-			ResourceType: req.ResourceType,
-			ErrorCode:    resource.OperationErrorCodeInvalidRequest,
-		}, nil
+		return readFailure(ctx, req, nil, resource.OperationErrorCodeInvalidRequest, "Invalid target config: %v", err), nil
 	}
 
 	// Create Cloudflare client
-	client, err := createCloudflareClient(config)
+	client, err := p.client(config)
 	if err != nil {
-		return &resource.ReadResult{
-			ResourceType: req.ResourceType,
-			ErrorCode:    resource.OperationErrorCodeInternalFailure,
-		}, nil
+		return readFailure(ctx, req, config, resource.OperationErrorCodeInternalFailure, "Failed to create Cloudflare client: %v", err), nil
+	}
+
+	// Resolve the zone the record lives in
+	zoneRef, recordID := splitNativeID(req.NativeID)
+	zone, err := p.resolveRecordZone(ctx, client, config, zoneRef)
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return readFailure(ctx, req, config, errorCode(err), "Failed to resolve zone: %v", err), nil
 	}
 
 	// Get the zone name for stripping from FQDN
-	zoneName, err := getZoneName(ctx, client, config.ZoneID)
+	zoneName, err := p.resolveZoneName(ctx, client, config, zone)
 	if err != nil {
-		return &resource.ReadResult{
-			ResourceType: req.ResourceType,
-			ErrorCode:    resource.OperationErrorCodeInternalFailure,
-		}, nil
+		p.cache.invalidateOnAuthError(config, err)
+		return readFailure(ctx, req, config, errorCode(err), "Failed to get zone name for zone %s: %v", zone.ID, err), nil
 	}
 
 	// Get the DNS record
-	rc := cloudflare.ZoneIdentifier(config.ZoneID)
-	record, err := client.GetDNSRecord(ctx, rc, req.NativeID)
+	record, err := getDNSRecord(ctx, client, zone.ID, recordID)
 	if err != nil {
-		// Check if record not found
-		if isNotFoundError(err) {
-			return &resource.ReadResult{
-				ResourceType: req.ResourceType,
-				ErrorCode:    resource.OperationErrorCodeNotFound,
-			}, nil
-		}
-		return &resource.ReadResult{
-			ResourceType: req.ResourceType,
-			ErrorCode:    resource.OperationErrorCodeInternalFailure,
-		}, nil
+		p.cache.invalidateOnAuthError(config, err)
+		return readFailure(ctx, req, config, errorCode(err), "Failed to read DNS record %s in zone %s: %v", recordID, zoneName, err), nil
+	}
+
+	// Look up the owner in the registry
+	entry, err := newRegistry(config, client, zone.ID, zoneName).lookup(ctx, record.Type, canonicalName(record.Name, zoneName))
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return readFailure(ctx, req, config, errorCode(err), "Failed to look up the owner of DNS record %s: %v", recordID, err), nil
 	}
 
 	// Convert to properties
-	props := recordToProperties(record, zoneName)
+	props := zoneRecordToProperties(record, zone, zoneName, entry)
 	propsJSON, err := propertiesToJSON(props)
 	if err != nil {
-		return &resource.ReadResult{
-			ResourceType: req.ResourceType,
-			ErrorCode:    resource.OperationErrorCodeInternalFailure,
-		}, nil
+		return readFailure(ctx, req, config, resource.OperationErrorCodeInternalFailure, "Failed to convert DNS record %s: %v", recordID, err), nil
 	}
 
 	return &resource.ReadResult{
@@ -437,6 +857,24 @@ func (p *Plugin) Read(ctx context.Context, req *resource.ReadRequest) (*resource
 	}, nil
 }
 
+// readFailure builds the result of a failed Read. ReadResult has no status
+// message, so the redacted message is logged for operators instead; a record
+// that no longer exists is expected and only logged at debug level.
+func readFailure(ctx context.Context, req *resource.ReadRequest, config *TargetConfig, code resource.OperationErrorCode, format string, args ...any) *resource.ReadResult {
+	message := redactSecrets(config, fmt.Sprintf(format, args...))
+	logger := plugin.LoggerFromContext(ctx)
+	if code == resource.OperationErrorCodeNotFound {
+		logger.Debug(message, "native_id", req.NativeID, "error_code", code)
+	} else {
+		logger.Error(message, "native_id", req.NativeID, "error_code", code)
+	}
+
+	return &resource.ReadResult{
+		ResourceType: req.ResourceType,
+		ErrorCode:    code,
+	}
+}
+
 // Update modifies an existing resource.
 func (p *Plugin) Update(ctx context.Context, req *resource.UpdateRequest) (*resource.UpdateResult, error) {
 	// Parse target config
@@ -478,7 +916,7 @@ func (p *Plugin) Update(ctx context.Context, req *resource.UpdateRequest) (*reso
 	}
 
 	// Create Cloudflare client
-	client, err := createCloudflareClient(config)
+	client, err := p.client(config)
 	if err != nil {
 		return &resource.UpdateResult{
 			ProgressResult: &resource.ProgressResult{
@@ -490,15 +928,71 @@ func (p *Plugin) Update(ctx context.Context, req *resource.UpdateRequest) (*reso
 		}, nil
 	}
 
-	// Update the DNS record
-	rc := cloudflare.ZoneIdentifier(config.ZoneID)
-	_, err = client.UpdateDNSRecord(ctx, rc, propsToUpdateParams(props, req.NativeID))
+	// Resolve the zone the record lives in
+	zoneRef, recordID := splitNativeID(req.NativeID)
+	zone, err := p.resolveRecordZone(ctx, client, config, zoneRef)
 	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
 		return &resource.UpdateResult{
 			ProgressResult: &resource.ProgressResult{
 				Operation:       resource.OperationUpdate,
 				OperationStatus: resource.OperationStatusFailure,
-				ErrorCode:       resource.OperationErrorCodeInternalFailure,
+				ErrorCode:       errorCode(err),
+				StatusMessage:   fmt.Sprintf("Failed to resolve zone: %v", err),
+			},
+		}, nil
+	}
+
+	// Make the name relative to the zone
+	zoneName, err := p.resolveZoneName(ctx, client, config, zone)
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return &resource.UpdateResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationUpdate,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       errorCode(err),
+				StatusMessage:   fmt.Sprintf("Failed to get zone name for zone %s: %v", zone.ID, err),
+			},
+		}, nil
+	}
+	if err := setCanonicalName(props, zoneName); err != nil {
+		return &resource.UpdateResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationUpdate,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       resource.OperationErrorCodeInvalidRequest,
+				StatusMessage:   fmt.Sprintf("Invalid properties: %v", err),
+			},
+		}, nil
+	}
+
+	// Update the DNS record, keeping its ownership marker, unless it
+	// conflicts with records in the zone or someone else owns it
+	var record dnsRecord
+	var entry *registryEntry
+	props.marker = ownershipMarker(config, req.Label, props, zoneName)
+	reg := newRegistry(config, client, zone.ID, zoneName)
+	atName, err := listRecordsAt(ctx, client, zone.ID, props.Name, zoneName)
+	if err == nil {
+		err = checkConflicts(ctx, client, zone.ID, props, zoneName, recordID, atName)
+	}
+	if err == nil {
+		entry, err = reg.check(ctx, props.RecordType, props.Name)
+	}
+	if err == nil {
+		record, err = updateDNSRecord(ctx, client, zone.ID, recordID, props, zoneName)
+	}
+	if err == nil {
+		err = reg.claim(ctx, entry)
+	}
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return &resource.UpdateResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationUpdate,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       errorCode(err),
 				StatusMessage:   fmt.Sprintf("Failed to update DNS record: %v", err),
 			},
 		}, nil
@@ -506,9 +1000,10 @@ func (p *Plugin) Update(ctx context.Context, req *resource.UpdateRequest) (*reso
 
 	return &resource.UpdateResult{
 		ProgressResult: &resource.ProgressResult{
-			Operation:       resource.OperationUpdate,
-			OperationStatus: resource.OperationStatusSuccess,
-			NativeID:        req.NativeID,
+			Operation:          resource.OperationUpdate,
+			OperationStatus:    resource.OperationStatusSuccess,
+			NativeID:           req.NativeID,
+			ResourceProperties: writtenProperties(record, zone, zoneName, entry),
 		},
 	}, nil
 }
@@ -529,7 +1024,7 @@ func (p *Plugin) Delete(ctx context.Context, req *resource.DeleteRequest) (*reso
 	}
 
 	// Create Cloudflare client
-	client, err := createCloudflareClient(config)
+	client, err := p.client(config)
 	if err != nil {
 		return &resource.DeleteResult{
 			ProgressResult: &resource.ProgressResult{
@@ -541,12 +1036,36 @@ func (p *Plugin) Delete(ctx context.Context, req *resource.DeleteRequest) (*reso
 		}, nil
 	}
 
+	// Resolve the zone the record lives in
+	zoneRef, recordID := splitNativeID(req.NativeID)
+	zone, err := p.resolveRecordZone(ctx, client, config, zoneRef)
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return &resource.DeleteResult{
+			ProgressResult: &resource.ProgressResult{
+				Operation:       resource.OperationDelete,
+				OperationStatus: resource.OperationStatusFailure,
+				ErrorCode:       errorCode(err),
+				StatusMessage:   fmt.Sprintf("Failed to resolve zone: %v", err),
+			},
+		}, nil
+	}
+
+	// Refuse to delete records owned by someone else
+	reg, err := p.zoneRegistry(ctx, client, config, zone)
+	var entry *registryEntry
+	if err == nil {
+		entry, err = reg.checkRecord(ctx, recordID)
+	}
+
 	// Delete the DNS record
-	rc := cloudflare.ZoneIdentifier(config.ZoneID)
-	err = client.DeleteDNSRecord(ctx, rc, req.NativeID)
+	if err == nil {
+		err = client.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(zone.ID), recordID)
+	}
 	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
 		// Check if record not found - consider it already deleted
-		if isNotFoundError(err) {
+		if errorCode(err) == resource.OperationErrorCodeNotFound {
 			return &resource.DeleteResult{
 				ProgressResult: &resource.ProgressResult{
 					Operation:       resource.OperationDelete,
@@ -558,12 +1077,19 @@ func (p *Plugin) Delete(ctx context.Context, req *resource.DeleteRequest) (*reso
 			ProgressResult: &resource.ProgressResult{
 				Operation:       resource.OperationDelete,
 				OperationStatus: resource.OperationStatusFailure,
-				ErrorCode:       resource.OperationErrorCodeInternalFailure,
+				ErrorCode:       errorCode(err),
 				StatusMessage:   fmt.Sprintf("Failed to delete DNS record: %v", err),
 			},
 		}, nil
 	}
 
+	// Remove the ownership record with the last record it covers. The record
+	// is gone at this point, so a failure only leaves the ownership record
+	// behind.
+	if err := reg.release(ctx, entry); err != nil {
+		plugin.LoggerFromContext(ctx).Warn(redactSecrets(config, err.Error()), "native_id", req.NativeID)
+	}
+
 	return &resource.DeleteResult{
 		ProgressResult: &resource.ProgressResult{
 			Operation:       resource.OperationDelete,
@@ -588,22 +1114,30 @@ func (p *Plugin) Status(ctx context.Context, req *resource.StatusRequest) (*reso
 // List returns all resource identifiers of a given type.
 // Called during discovery to find unmanaged resources.
 func (p *Plugin) List(ctx context.Context, req *resource.ListRequest) (*resource.ListResult, error) {
-	// Parse target config
-	config, err := parseTargetConfig(req.TargetConfig)
-	if err != nil {
+	// Only DNS records are managed by this plugin
+	if req.ResourceType != dnsRecordResourceType {
 		return &resource.ListResult{
 			NativeIDs:     []string{},
 			NextPageToken: nil,
 		}, nil
 	}
 
+	// Parse target config
+	config, err := parseTargetConfig(req.TargetConfig)
+	if err != nil {
+		return nil, listFailure(nil, resource.OperationErrorCodeInvalidRequest, "invalid target config: %v", err)
+	}
+
 	// Create Cloudflare client
-	client, err := createCloudflareClient(config)
+	client, err := p.client(config)
 	if err != nil {
-		return &resource.ListResult{
-			NativeIDs:     []string{},
-			NextPageToken: nil,
-		}, nil
+		return nil, listFailure(config, resource.OperationErrorCodeInternalFailure, "failed to create Cloudflare client: %v", err)
+	}
+
+	// Narrow the listing to one record type if requested
+	recordType := strings.ToUpper(req.AdditionalProperties["record_type"])
+	if recordType != "" && !supportedRecordTypes[recordType] {
+		return nil, listFailure(config, resource.OperationErrorCodeInvalidRequest, "unsupported record type: %s", recordType)
 	}
 
 	// Set up pagination
@@ -612,37 +1146,56 @@ func (p *Plugin) List(ctx context.Context, req *resource.ListRequest) (*resource
 		pageSize = int(req.PageSize)
 	}
 
-	page := 1
-	if req.PageToken != nil && *req.PageToken != "" {
-		// Parse page number from token (ignore errors, default to page 1)
-		_, _ = fmt.Sscanf(*req.PageToken, "%d", &page)
+	// Multi-zone targets list each allowed zone in turn
+	zoneRefs := []string{""}
+	if config.isMultiZone() {
+		zoneRefs = config.Zones
+	}
+	zoneIndex, page := parsePageToken(req.PageToken)
+	if zoneIndex >= len(zoneRefs) {
+		return &resource.ListResult{
+			NativeIDs:     []string{},
+			NextPageToken: nil,
+		}, nil
+	}
+
+	// Resolve the zone
+	zone, err := p.resolveRecordZone(ctx, client, config, zoneRefs[zoneIndex])
+	if err != nil {
+		p.cache.invalidateOnAuthError(config, err)
+		return nil, listFailure(config, errorCode(err), "failed to resolve zone: %v", err)
 	}
 
 	// List DNS records
-	rc := cloudflare.ZoneIdentifier(config.ZoneID)
+	rc := cloudflare.ZoneIdentifier(zone.ID)
 	records, resultInfo, err := client.ListDNSRecords(ctx, rc, cloudflare.ListDNSRecordsParams{
+		Type: recordType,
 		ResultInfo: cloudflare.ResultInfo{
 			Page:    page,
 			PerPage: pageSize,
 		},
 	})
 	if err != nil {
-		return &resource.ListResult{
-			NativeIDs:     []string{},
-			NextPageToken: nil,
-		}, nil
+		p.cache.invalidateOnAuthError(config, err)
+		return nil, listFailure(config, errorCode(err), "failed to list DNS records in zone %s: %v", zone.ID, err)
 	}
 
-	// Extract record IDs
+	// Extract IDs of the records the plugin can manage
 	nativeIDs := make([]string, 0, len(records))
 	for _, record := range records {
-		nativeIDs = append(nativeIDs, record.ID)
+		if !isManageable(record) {
+			continue
+		}
+		nativeIDs = append(nativeIDs, joinNativeID(zone.Ref, record.ID))
 	}
 
-	// Determine if there are more pages
+	// Determine if there are more pages, in this zone or the next one
 	var nextPageToken *string
 	if resultInfo != nil && resultInfo.Page < resultInfo.TotalPages {
-		token := fmt.Sprintf("%d", resultInfo.Page+1)
+		token := formatPageToken(config, zoneIndex, resultInfo.Page+1)
+		nextPageToken = &token
+	} else if zoneIndex+1 < len(zoneRefs) {
+		token := formatPageToken(config, zoneIndex+1, 1)
 		nextPageToken = &token
 	}
 
@@ -8,6 +8,8 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -40,6 +42,61 @@ func TestParseTargetConfig_MissingAPIToken(t *testing.T) {
 	}
 }
 
+func TestParseTargetConfig_ZoneName(t *testing.T) {
+	configJSON := `{"api_token": "test-token-123", "zone_name": "example.com"}`
+
+	config, err := parseTargetConfig(json.RawMessage(configJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.ZoneName != "example.com" {
+		t.Errorf("expected ZoneName 'example.com', got '%s'", config.ZoneName)
+	}
+}
+
+func TestParseTargetConfig_ZoneIDAndZoneName(t *testing.T) {
+	configJSON := `{"api_token": "test-token-123", "zone_id": "zone-abc-456", "zone_name": "example.com"}`
+
+	_, err := parseTargetConfig(json.RawMessage(configJSON))
+	if err == nil {
+		t.Fatal("expected error when both zone_id and zone_name are set, got nil")
+	}
+}
+
+func TestParseTargetConfig_MultiZone(t *testing.T) {
+	configJSON := `{"api_token": "test-token-123", "account_id": "acct-1", "zones": ["example.com", "zone-abc-456"]}`
+
+	config, err := parseTargetConfig(json.RawMessage(configJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !config.isMultiZone() {
+		t.Error("expected multi-zone target")
+	}
+	if len(config.Zones) != 2 {
+		t.Errorf("expected 2 zones, got %d", len(config.Zones))
+	}
+}
+
+func TestParseTargetConfig_InvalidMultiZone(t *testing.T) {
+	tests := map[string]string{
+		"missing account_id": `{"api_token": "t", "zones": ["example.com"]}`,
+		"combined with zone": `{"api_token": "t", "account_id": "a", "zone_id": "z", "zones": ["example.com"]}`,
+		"empty entry":        `{"api_token": "t", "account_id": "a", "zones": [""]}`,
+	}
+
+	for name, configJSON := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseTargetConfig(json.RawMessage(configJSON))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
 func TestParseTargetConfig_MissingZoneID(t *testing.T) {
 	configJSON := `{"api_token": "test-token-123"}`
 
@@ -49,6 +106,53 @@ func TestParseTargetConfig_MissingZoneID(t *testing.T) {
 	}
 }
 
+func TestParseTargetConfig_HTTPSettings(t *testing.T) {
+	configJSON := `{
+		"api_token": "test-token-123",
+		"zone_id": "zone-abc-456",
+		"base_url": "https://cloudflare-proxy.internal/client/v4",
+		"proxy_url": "http://egress.internal:3128",
+		"request_timeout_seconds": 30
+	}`
+
+	config, err := parseTargetConfig(json.RawMessage(configJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.BaseURL != "https://cloudflare-proxy.internal/client/v4" {
+		t.Errorf("expected BaseURL 'https://cloudflare-proxy.internal/client/v4', got '%s'", config.BaseURL)
+	}
+	if config.ProxyURL != "http://egress.internal:3128" {
+		t.Errorf("expected ProxyURL 'http://egress.internal:3128', got '%s'", config.ProxyURL)
+	}
+	if config.RequestTimeoutSeconds != 30 {
+		t.Errorf("expected RequestTimeoutSeconds 30, got %d", config.RequestTimeoutSeconds)
+	}
+}
+
+func TestParseTargetConfig_InvalidHTTPSettings(t *testing.T) {
+	tests := map[string]string{
+		"relative base_url":  `{"api_token": "t", "zone_id": "z", "base_url": "/client/v4"}`,
+		"ftp base_url":       `{"api_token": "t", "zone_id": "z", "base_url": "ftp://example.com"}`,
+		"proxy without host": `{"api_token": "t", "zone_id": "z", "proxy_url": "http://"}`,
+		"negative timeout":   `{"api_token": "t", "zone_id": "z", "request_timeout_seconds": -1}`,
+		"invalid ca_bundle":  `{"api_token": "t", "zone_id": "z", "ca_bundle": "not a certificate"}`,
+		"negative attempts":  `{"api_token": "t", "zone_id": "z", "retry_max_attempts": -1}`,
+		"negative max delay": `{"api_token": "t", "zone_id": "z", "retry_max_delay_seconds": -1}`,
+		"negative rate":      `{"api_token": "t", "zone_id": "z", "max_requests_per_second": -1}`,
+	}
+
+	for name, configJSON := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseTargetConfig(json.RawMessage(configJSON))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
 func TestParseTargetConfig_InvalidJSON(t *testing.T) {
 	configJSON := `{invalid json}`
 
@@ -155,6 +259,43 @@ func TestParseProperties_DefaultValues(t *testing.T) {
 	}
 }
 
+func TestParseProperties_CanonicalName(t *testing.T) {
+	for _, name := range []string{"Bücher.Example.com.", "XN--BCHER-KVA.example.com", "bücher.example.com"} {
+		t.Run(name, func(t *testing.T) {
+			props, err := parseProperties(json.RawMessage(fmt.Sprintf(`{"record_type": "A", "name": %q, "content": "192.0.2.1"}`, name)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Name != "bücher.example.com" {
+				t.Errorf("expected name %q, got %q", "bücher.example.com", props.Name)
+			}
+		})
+	}
+}
+
+func TestParseProperties_CanonicalZone(t *testing.T) {
+	tests := []struct {
+		zone     string
+		expected string
+	}{
+		{"Example.COM", "example.com"},
+		{" example.com. ", "example.com"},
+		{"023e105f4ecef8ad9ca31a8372d0c353", "023e105f4ecef8ad9ca31a8372d0c353"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			props, err := parseProperties(json.RawMessage(fmt.Sprintf(`{"record_type": "A", "name": "www", "zone": %q, "content": "192.0.2.1"}`, tt.zone)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Zone == nil || *props.Zone != tt.expected {
+				t.Errorf("expected zone %q, got %v", tt.expected, props.Zone)
+			}
+		})
+	}
+}
+
 func TestParseProperties_MissingRecordType(t *testing.T) {
 	propsJSON := `{
 		"name": "test.example.com",
@@ -191,6 +332,32 @@ func TestParseProperties_MissingContent(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Native ID Tests
+// =============================================================================
+
+func TestSplitNativeID(t *testing.T) {
+	tests := []struct {
+		nativeID string
+		zoneRef  string
+		recordID string
+	}{
+		{"abc123", "", "abc123"},
+		{"example.com/abc123", "example.com", "abc123"},
+		{"zone-abc-456/abc123", "zone-abc-456", "abc123"},
+	}
+
+	for _, tt := range tests {
+		zoneRef, recordID := splitNativeID(tt.nativeID)
+		if zoneRef != tt.zoneRef || recordID != tt.recordID {
+			t.Errorf("splitNativeID(%q) = (%q, %q), want (%q, %q)", tt.nativeID, zoneRef, recordID, tt.zoneRef, tt.recordID)
+		}
+		if joined := joinNativeID(zoneRef, recordID); joined != tt.nativeID {
+			t.Errorf("joinNativeID(%q, %q) = %q, want %q", zoneRef, recordID, joined, tt.nativeID)
+		}
+	}
+}
+
 // =============================================================================
 // Validation Tests
 // =============================================================================
@@ -302,24 +469,38 @@ func TestValidateProperties_ProxiedOnNonProxyableType(t *testing.T) {
 func TestValidateProperties_AllSupportedTypes(t *testing.T) {
 	tests := []struct {
 		recordType string
+		name       string
+		content    string
 		priority   *int
 	}{
-		{"A", nil},
-		{"AAAA", nil},
-		{"CNAME", nil},
-		{"MX", intPtr(10)},
-		{"TXT", nil},
-		{"NS", nil},
-		{"CAA", nil},
-		{"SRV", intPtr(10)},
+		{"A", "test.example.com", "192.0.2.1", nil},
+		{"AAAA", "test.example.com", "2001:db8::1", nil},
+		{"CNAME", "test.example.com", "target.example.com", nil},
+		{"MX", "test.example.com", "mail.example.com", intPtr(10)},
+		{"TXT", "test.example.com", "test-content", nil},
+		{"NS", "test.example.com", "ns1.example.com", nil},
+		{"CAA", "test.example.com", `0 issue "letsencrypt.org"`, nil},
+		{"SRV", "_sip._tcp.example.com", "5 5060 sip.example.com", intPtr(10)},
+		{"HTTPS", "test.example.com", `1 . alpn="h2"`, nil},
+		{"SVCB", "_dns.example.com", "1 dns.example.com", nil},
+		{"TLSA", "_443._tcp.example.com", "3 1 1 " + testSHA256, nil},
+		{"SMIMEA", "test.example.com", "3 0 0 3082", nil},
+		{"SSHFP", "test.example.com", "4 2 " + testSHA256, nil},
+		{"CERT", "test.example.com", "1 0 0 MIIBIjAN", nil},
+		{"DS", "test.example.com", "2371 13 2 " + testSHA256, nil},
+		{"DNSKEY", "test.example.com", "257 3 13 AwEAAQ==", nil},
+		{"PTR", "1.2.0.192.in-addr.arpa", "www.example.com", nil},
+		{"LOC", "test.example.com", "51 30 12.748 N 0 7 39.611 W 0.00m", nil},
+		{"NAPTR", "test.example.com", `100 10 "S" "SIP+D2U" "" _sip._udp.example.com`, nil},
+		{"URI", "_ftp._tcp.example.com", `1 "ftp://ftp.example.com/public"`, intPtr(10)},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.recordType, func(t *testing.T) {
 			props := &DNSRecordProperties{
 				RecordType: tt.recordType,
-				Name:       "test.example.com",
-				Content:    "test-content",
+				Name:       tt.name,
+				Content:    tt.content,
 				TTL:        300,
 				Priority:   tt.priority,
 			}
@@ -335,3 +516,237 @@ func TestValidateProperties_AllSupportedTypes(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestValidateProperties_InvalidContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		props    DNSRecordProperties
+		expected string
+	}{
+		{"A not an IP", DNSRecordProperties{RecordType: "A", Content: "not-an-ip", TTL: 1}, `invalid A record content "not-an-ip": must be an IPv4 address`},
+		{"A with IPv6", DNSRecordProperties{RecordType: "A", Content: "2001:db8::1", TTL: 1}, "must be an IPv4 address"},
+		{"AAAA with IPv4", DNSRecordProperties{RecordType: "AAAA", Content: "192.0.2.1", TTL: 1}, "must be an IPv6 address"},
+		{"AAAA with mapped IPv4", DNSRecordProperties{RecordType: "AAAA", Content: "::ffff:192.0.2.1", TTL: 1}, "must be an IPv6 address"},
+		{"CNAME with space", DNSRecordProperties{RecordType: "CNAME", Content: "target example.com", TTL: 1}, `label "target example" contains invalid character ' '`},
+		{"MX with underscore", DNSRecordProperties{RecordType: "MX", Content: "mail_1.example.com", TTL: 1, Priority: intPtr(10)}, `label "mail_1" contains invalid character '_'`},
+		{"NS with hyphen", DNSRecordProperties{RecordType: "NS", Content: "-ns1.example.com", TTL: 1}, "must not start or end with a hyphen"},
+		{"NS with empty label", DNSRecordProperties{RecordType: "NS", Content: "ns1..example.com", TTL: 1}, "must not contain empty labels"},
+		{"NS with long label", DNSRecordProperties{RecordType: "NS", Content: strings.Repeat("a", 64) + ".example.com", TTL: 1}, "exceeds 63 characters"},
+		{"CNAME with disallowed code point", DNSRecordProperties{RecordType: "CNAME", Content: "☃.example.com", TTL: 1}, `label "☃" is not a valid internationalized label`},
+		{"TXT too long", DNSRecordProperties{RecordType: "TXT", Content: strings.Repeat("a", 2049), TTL: 1}, "2075 characters exceeds the limit of 2048 once quoted"},
+		{"MX priority out of range", DNSRecordProperties{RecordType: "MX", Content: "mail.example.com", TTL: 1, Priority: intPtr(65536)}, "priority must be between 0 and 65535, got 65536"},
+		{"TTL too short", DNSRecordProperties{RecordType: "A", Content: "192.0.2.1", TTL: 10}, "ttl must be 1 (automatic) or between 30 and 86400, got 10"},
+		{"TTL too long", DNSRecordProperties{RecordType: "A", Content: "192.0.2.1", TTL: 86401}, "got 86401"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := tt.props
+			props.Name = "test.example.com"
+
+			err := validateProperties(&props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateProperties_InvalidName(t *testing.T) {
+	props := &DNSRecordProperties{RecordType: "A", Name: "☃.example.com", Content: "192.0.2.1", TTL: 1}
+
+	err := validateProperties(props)
+	if err == nil {
+		t.Fatal("expected error for disallowed code point, got nil")
+	}
+	if !strings.Contains(err.Error(), `invalid name "☃.example.com"`) {
+		t.Errorf("expected error naming the record name, got %q", err.Error())
+	}
+}
+
+func TestValidateProperties_RecordNames(t *testing.T) {
+	valid := []string{"@", "www", "_dmarc", "_sip._tcp", "*", "*.dev", "bücher", "www.example.com."}
+	for _, name := range valid {
+		props := &DNSRecordProperties{RecordType: "A", Name: name, Content: "192.0.2.1", TTL: 1}
+		if err := validateProperties(props); err != nil {
+			t.Errorf("unexpected error for name %q: %v", name, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"foo bar":               `label "foo bar" contains invalid character ' '`,
+		"a..b":                  "empty labels",
+		"-www":                  "must not start or end with a hyphen",
+		"www.*":                 `label "*" contains invalid character '*'`,
+		strings.Repeat("a", 64): "exceeds 63 characters",
+	}
+	for name, expected := range invalid {
+		props := &DNSRecordProperties{RecordType: "A", Name: name, Content: "192.0.2.1", TTL: 1}
+		err := validateProperties(props)
+		if err == nil {
+			t.Errorf("expected error for name %q, got nil", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), "invalid name") || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q for name %q, got %q", expected, name, err.Error())
+		}
+	}
+}
+
+func TestValidateProperties_CommentLength(t *testing.T) {
+	fits := strings.Repeat("é", 74)
+	props := &DNSRecordProperties{RecordType: "A", Name: "www", Content: "192.0.2.1", TTL: 1, Comment: &fits}
+	if err := validateProperties(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if marked := markComment(fits, "formae:3f2a9c0b17d4e865"); len([]rune(marked)) != maxCommentLen {
+		t.Errorf("expected marked comment of %d characters, got %d", maxCommentLen, len([]rune(marked)))
+	}
+
+	tooLong := fits + "x"
+	props.Comment = &tooLong
+	err := validateProperties(props)
+	if err == nil {
+		t.Fatal("expected error for comment too long for the marker, got nil")
+	}
+	if !strings.Contains(err.Error(), "comment must be at most 74 characters") {
+		t.Errorf("expected error naming the limit, got %q", err.Error())
+	}
+}
+
+func TestValidateProperties_InvalidTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []DNSRecordTag
+		expected string
+	}{
+		{"empty key", []DNSRecordTag{{Value: "payments"}}, "tag key must not be empty"},
+		{"separator in key", []DNSRecordTag{{Key: "team:payments"}}, `tag key "team:payments" must not contain ':'`},
+		{"duplicate key", []DNSRecordTag{{Key: "team", Value: "a"}, {Key: "env"}, {Key: "team", Value: "b"}}, `duplicate tag key "team"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "A", Name: "www", Content: "192.0.2.1", TTL: 1, Tags: tt.tags}
+
+			err := validateProperties(props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateProperties_Settings(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordType string
+		content    string
+		proxied    bool
+		settings   RecordSettings
+		expected   string
+	}{
+		{"flatten CNAME", "CNAME", "target.example.net", false, RecordSettings{FlattenCNAME: true}, ""},
+		{"flatten A", "A", "192.0.2.1", false, RecordSettings{FlattenCNAME: true}, "setting flatten_cname does not apply to A records"},
+		{"flatten proxied CNAME", "CNAME", "target.example.net", true, RecordSettings{FlattenCNAME: true}, "only applies to records that are not proxied"},
+		{"ipv4_only proxied A", "A", "192.0.2.1", true, RecordSettings{IPv4Only: true}, ""},
+		{"ipv6_only proxied CNAME", "CNAME", "target.example.net", true, RecordSettings{IPv6Only: true}, ""},
+		{"ipv4_only unproxied", "A", "192.0.2.1", false, RecordSettings{IPv4Only: true}, "only apply to proxied records"},
+		{"ipv6_only TXT", "TXT", "hello", false, RecordSettings{IPv6Only: true}, "setting ipv6_only does not apply to TXT records"},
+		{"ipv4_only and ipv6_only", "AAAA", "2001:db8::1", true, RecordSettings{IPv4Only: true, IPv6Only: true}, "cannot both be set"},
+		{"several settings on TXT", "TXT", "hello", false, RecordSettings{FlattenCNAME: true, IPv4Only: true, IPv6Only: true}, "setting flatten_cname does not apply to TXT records"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := tt.settings
+			props := &DNSRecordProperties{RecordType: tt.recordType, Name: "www", Content: tt.content, TTL: 1, Proxied: tt.proxied, Settings: &settings}
+
+			err := validateProperties(props)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateProperties_SettingsOffAreDropped(t *testing.T) {
+	props := &DNSRecordProperties{RecordType: "TXT", Name: "www", Content: "hello", TTL: 1, Settings: &RecordSettings{}}
+
+	if err := validateProperties(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Settings != nil {
+		t.Errorf("expected settings that are all off to be dropped, got %+v", props.Settings)
+	}
+}
+
+func TestRecordTags(t *testing.T) {
+	tags := recordTags([]string{"team:payments", "pci", "url:https://example.com"})
+
+	expected := []DNSRecordTag{{Key: "pci"}, {Key: "team", Value: "payments"}, {Key: "url", Value: "https://example.com"}}
+	if len(tags) != len(expected) {
+		t.Fatalf("expected %d tags, got %v", len(expected), tags)
+	}
+	for i := range expected {
+		if tags[i] != expected[i] {
+			t.Errorf("expected tag %v, got %v", expected[i], tags[i])
+		}
+	}
+	if got := cloudflareTags(tags); strings.Join(got, ",") != "pci,team:payments,url:https://example.com" {
+		t.Errorf("expected tags to round-trip, got %v", got)
+	}
+}
+
+func TestDiscoveryFilters_SkipDiscoveryTag(t *testing.T) {
+	filters := (&Plugin{}).DiscoveryFilters()
+	if len(filters) != 1 || len(filters[0].Conditions) != 1 {
+		t.Fatalf("expected one filter with one condition, got %v", filters)
+	}
+	condition := filters[0].Conditions[0]
+	if condition.PropertyPath != "$.tags[?(@.key=='skip-discovery')].value" || condition.PropertyValue != "true" {
+		t.Errorf("expected skip-discovery tag condition, got %+v", condition)
+	}
+}
+
+func TestValidateProperties_CanonicalContent(t *testing.T) {
+	tests := []struct {
+		recordType string
+		content    string
+		expected   string
+	}{
+		{"AAAA", "2001:DB8:0:0::1", "2001:db8::1"},
+		{"CNAME", "_acme-challenge.example.net", "_acme-challenge.example.net"},
+		{"MX", ".", "."},
+		{"CNAME", "Target.Example.com.", "target.example.com"},
+		{"NS", "ns1.Bücher.example.", "ns1.xn--bcher-kva.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: tt.recordType, Name: "test.example.com", Content: tt.content, TTL: 300, Priority: intPtr(0)}
+			if tt.recordType != "MX" {
+				props.Priority = nil
+			}
+
+			if err := validateProperties(props); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Content != tt.expected {
+				t.Errorf("expected content %q, got %q", tt.expected, props.Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Before writing a record, Create and Update check the zone for records it
+// cannot coexist with, so that conflicts fail fast with the IDs of the
+// records involved instead of surfacing as an API error mid-apply:
+//
+//   - A CNAME record cannot share its name with any other record, including
+//     another CNAME record.
+//   - A CNAME record at the zone apex must be flattened (flatten_cname) or
+//     proxied, as the apex always holds the zone's SOA and NS records. It is
+//     then answered with addresses, so it only conflicts with A, AAAA and
+//     other CNAME records.
+//   - An NS record below the apex delegates its name. Only NS and DS records
+//     may share the name, and no records may exist below it.
+//
+// NS records at the apex are the zone's own name servers and delegate nothing.
+
+// errConflictingRecords is returned when a record cannot coexist with records
+// already in the zone.
+var errConflictingRecords = errors.New("conflicting records")
+
+// Reasons records conflict, in the order they are reported.
+const (
+	conflictCNAME      = "a CNAME record cannot share its name with other records, or with A, AAAA and CNAME records at the apex"
+	conflictDelegation = "an NS delegation can only share its name with NS and DS records"
+	conflictDelegated  = "records cannot be placed below an NS delegation"
+)
+
+// checkConflicts returns errConflictingRecords if the record described by
+// props cannot coexist with the records in the zone. atName are the records
+// at its name, see listRecordsAt. recordID is the record being updated, or ""
+// on create. The name in props must be canonical.
+func checkConflicts(ctx context.Context, client *cloudflare.API, zoneID string, props *DNSRecordProperties, zoneName, recordID string, atName []cloudflare.DNSRecord) error {
+	if err := checkApexCNAME(props); err != nil {
+		return err
+	}
+
+	records := atName
+	switch {
+	case props.Name == apexName:
+		// Only records at the apex itself can conflict
+	case props.RecordType == "NS":
+		// A delegation conflicts with records anywhere below it
+		below, err := listRecordsBelow(ctx, client, zoneID, recordFQDN(props.Name, zoneName))
+		if err != nil {
+			return err
+		}
+		records = append(slices.Clip(atName), below...)
+	default:
+		delegations, _, err := client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{Type: "NS"})
+		if err != nil {
+			return err
+		}
+		records = append(slices.Clip(atName), delegations...)
+	}
+
+	return findConflicts(props, records, zoneName, recordID)
+}
+
+// checkApexCNAME rejects a CNAME record at the zone apex that is neither
+// flattened nor proxied.
+func checkApexCNAME(props *DNSRecordProperties) error {
+	if props.RecordType != "CNAME" || props.Name != apexName || props.Proxied {
+		return nil
+	}
+	if props.Settings != nil && props.Settings.FlattenCNAME {
+		return nil
+	}
+	return fmt.Errorf("%w: a CNAME record at the zone apex must set flatten_cname or be proxied, as it cannot coexist with the zone's SOA and NS records", errConflictingRecords)
+}
+
+// findConflicts returns errConflictingRecords naming the records that the
+// record described by props cannot coexist with. Records may be anywhere in
+// the zone. On create, records that Create returns or adopts instead of
+// creating a new one are not conflicts.
+func findConflicts(props *DNSRecordProperties, records []cloudflare.DNSRecord, zoneName, recordID string) error {
+	reasons := []string{conflictCNAME, conflictDelegation, conflictDelegated}
+	conflicts := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		if record.ID == recordID || seen[record.ID] || isCompanionRecord(record) {
+			continue
+		}
+		seen[record.ID] = true
+		if recordID == "" && isSameRecord(record, props, zoneName) {
+			continue
+		}
+
+		name := canonicalName(record.Name, zoneName)
+		reason := ""
+		switch {
+		case name == props.Name && isCNAMEConflict(props.RecordType, record.Type, name):
+			reason = conflictCNAME
+		case name == props.Name && props.Name != apexName && isDelegationConflict(props.RecordType, record.Type):
+			reason = conflictDelegation
+		case props.RecordType == "NS" && isBelow(name, props.Name):
+			reason = conflictDelegated
+		case record.Type == "NS" && isBelow(props.Name, name):
+			reason = conflictDelegated
+		default:
+			continue
+		}
+		conflicts[reason] = append(conflicts[reason], fmt.Sprintf("%s record %s at %s", record.Type, record.ID, name))
+	}
+
+	var messages []string
+	for _, reason := range reasons {
+		if len(conflicts[reason]) > 0 {
+			messages = append(messages, reason+": "+strings.Join(conflicts[reason], ", "))
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errConflictingRecords, strings.Join(messages, "; "))
+}
+
+// isCNAMEConflict reports whether records of two types conflict at a name
+// because one of them is a CNAME record.
+func isCNAMEConflict(a, b, name string) bool {
+	if a != "CNAME" && b != "CNAME" {
+		return false
+	}
+	if name == apexName {
+		return addressRecordTypes[a] && addressRecordTypes[b]
+	}
+	return true
+}
+
+// addressRecordTypes are the types a flattened CNAME record is answered as.
+var addressRecordTypes = map[string]bool{"A": true, "AAAA": true, "CNAME": true}
+
+// isDelegationConflict reports whether records of two types conflict at the
+// name of an NS delegation: an NS record only shares its name with NS and DS
+// records.
+func isDelegationConflict(a, b string) bool {
+	switch {
+	case a == "NS":
+		return b != "NS" && b != "DS"
+	case b == "NS":
+		return a != "DS"
+	}
+	return false
+}
+
+// isBelow reports whether a canonical name is strictly below another name
+// other than the apex.
+func isBelow(name, parent string) bool {
+	return parent != apexName && strings.HasSuffix(name, "."+parent)
+}
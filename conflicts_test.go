@@ -0,0 +1,100 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestFindConflicts(t *testing.T) {
+	zone := []cloudflare.DNSRecord{
+		{ID: "a-www", Type: "A", Name: "www.example.com", Content: "192.0.2.1"},
+		{ID: "txt-www", Type: "TXT", Name: "www.example.com", Content: "hello"},
+		{ID: "cname-docs", Type: "CNAME", Name: "docs.example.com", Content: "docs.example.net"},
+		{ID: "ns-dev", Type: "NS", Name: "dev.example.com", Content: "ns1.example.net"},
+		{ID: "ds-dev", Type: "DS", Name: "dev.example.com", Content: "2371 13 2 abcd"},
+		{ID: "ns-apex", Type: "NS", Name: "example.com", Content: "ns1.example.net"},
+		{ID: "mx-apex", Type: "MX", Name: "example.com", Content: "mail.example.com"},
+		{ID: "a-apex", Type: "A", Name: "example.com", Content: "192.0.2.3"},
+		{ID: "cname-shop", Type: "CNAME", Name: "shop.example.com", Content: "xn--bcher-kva.example"},
+		{ID: "a-api-eu", Type: "A", Name: "api.eu.example.com", Content: "192.0.2.2"},
+		{ID: "owner", Type: "TXT", Name: "_formae-owner.a.eu.example.com", Content: "heritage=formae,formae/owner=prod"},
+	}
+
+	tests := []struct {
+		name      string
+		props     DNSRecordProperties
+		recordID  string
+		conflicts []string // IDs named in the error, none if empty
+	}{
+		{"A next to A", DNSRecordProperties{RecordType: "A", Name: "www", Content: "192.0.2.9"}, "", nil},
+		{"CNAME next to A and TXT", DNSRecordProperties{RecordType: "CNAME", Name: "www", Content: "web.example.net"}, "", []string{"a-www", "txt-www"}},
+		{"A next to CNAME", DNSRecordProperties{RecordType: "A", Name: "docs", Content: "192.0.2.9"}, "", []string{"cname-docs"}},
+		{"second CNAME", DNSRecordProperties{RecordType: "CNAME", Name: "docs", Content: "docs.example.org"}, "", []string{"cname-docs"}},
+		{"identical CNAME on create", DNSRecordProperties{RecordType: "CNAME", Name: "docs", Content: "docs.example.net"}, "", nil},
+		{"identical IDN CNAME on create", DNSRecordProperties{RecordType: "CNAME", Name: "shop", Content: "xn--bcher-kva.example"}, "", nil},
+		{"other IDN CNAME", DNSRecordProperties{RecordType: "CNAME", Name: "shop", Content: "xn--mnchen-3ya.example"}, "", []string{"cname-shop"}},
+		{"CNAME updating itself", DNSRecordProperties{RecordType: "CNAME", Name: "docs", Content: "docs.example.org"}, "cname-docs", nil},
+		{"second NS at delegation", DNSRecordProperties{RecordType: "NS", Name: "dev", Content: "ns2.example.net"}, "", nil},
+		{"DS at delegation", DNSRecordProperties{RecordType: "DS", Name: "dev", Content: "2371 13 2 ef01"}, "", nil},
+		{"A at delegation", DNSRecordProperties{RecordType: "A", Name: "dev", Content: "192.0.2.9"}, "", []string{"ns-dev"}},
+		{"A below delegation", DNSRecordProperties{RecordType: "A", Name: "app.dev", Content: "192.0.2.9"}, "", []string{"ns-dev"}},
+		{"NS at records", DNSRecordProperties{RecordType: "NS", Name: "www", Content: "ns1.example.net"}, "", []string{"a-www", "txt-www"}},
+		{"NS above records", DNSRecordProperties{RecordType: "NS", Name: "eu", Content: "ns1.example.net"}, "", []string{"a-api-eu"}},
+		{"MX at apex", DNSRecordProperties{RecordType: "MX", Name: "@", Content: "mx.example.com"}, "", nil},
+		{"CNAME at apex", DNSRecordProperties{RecordType: "CNAME", Name: "@", Content: "app.example.net"}, "", []string{"a-apex"}},
+		{"AAAA at apex", DNSRecordProperties{RecordType: "AAAA", Name: "@", Content: "2001:db8::1"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := findConflicts(&tt.props, zone, "example.com", tt.recordID)
+			if len(tt.conflicts) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errConflictingRecords) {
+				t.Fatalf("expected conflicting records, got %v", err)
+			}
+			for _, record := range zone {
+				named := strings.Contains(err.Error(), " "+record.ID+" ")
+				if expected := slices.Contains(tt.conflicts, record.ID); named != expected {
+					t.Errorf("expected %s to be named %v, got %q", record.ID, expected, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestCheckApexCNAME(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   DNSRecordProperties
+		wantErr bool
+	}{
+		{"plain", DNSRecordProperties{RecordType: "CNAME", Name: "@"}, true},
+		{"flattened", DNSRecordProperties{RecordType: "CNAME", Name: "@", Settings: &RecordSettings{FlattenCNAME: true}}, false},
+		{"proxied", DNSRecordProperties{RecordType: "CNAME", Name: "@", Proxied: true}, false},
+		{"below apex", DNSRecordProperties{RecordType: "CNAME", Name: "www"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkApexCNAME(&tt.props)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
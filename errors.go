@@ -0,0 +1,212 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/platform-engineering-labs/formae/pkg/plugin/resource"
+)
+
+// Cloudflare API error codes the plugin classifies explicitly.
+// See https://developers.cloudflare.com/api/ for the full list.
+const (
+	cfErrRateLimited        = 971   // Please wait and consider throttling your request speed
+	cfErrInvalidHeaders     = 6003  // Invalid request headers
+	cfErrInvalidAuthFormat  = 6111  // Invalid format for Authorization header
+	cfErrInvalidZone        = 7003  // Could not route to /zones/..., perhaps your object identifier is invalid?
+	cfErrInvalidAccessToken = 9109  // Invalid access token
+	cfErrAuthentication     = 10000 // Authentication error
+	cfErrRecordNotFound     = 81044 // Record does not exist
+	cfErrCNAMEConflict      = 81053 // An A, AAAA, or CNAME record with that host already exists
+	cfErrCNAMEExists        = 81054 // A CNAME record with that host already exists
+	cfErrRecordExists       = 81057 // Record already exists
+	cfErrIdenticalRecord    = 81058 // An identical record already exists
+)
+
+// cloudflareError is implemented by the typed errors cloudflare-go returns
+// for API error responses.
+type cloudflareError interface {
+	error
+	ErrorCodes() []int
+}
+
+// cfRetryExhaustedPattern matches the errors cloudflare-go returns once its
+// own retries are exhausted. These are plain errors without a type, so they
+// are recognized by their text.
+var cfRetryExhaustedPattern = regexp.MustCompile(`\(HTTP (\d{3})\), please try again later`)
+
+// errorCode maps an error returned while talking to Cloudflare to the formae
+// operation error code that best describes it, so formae can retry transient
+// failures and report permission problems as such.
+func errorCode(err error) resource.OperationErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, errZoneNotResolved) || errors.Is(err, errZoneNotPermitted) || errors.Is(err, errConflictingRecords) {
+		return resource.OperationErrorCodeInvalidRequest
+	}
+	if errors.Is(err, errIdenticalRecord) {
+		return resource.OperationErrorCodeAlreadyExists
+	}
+	if errors.Is(err, errNotOwner) {
+		return resource.OperationErrorCodeResourceConflict
+	}
+
+	var authorizationErr *cloudflare.AuthorizationError
+	var authenticationErr *cloudflare.AuthenticationError
+	var notFoundErr *cloudflare.NotFoundError
+	var ratelimitErr *cloudflare.RatelimitError
+	var throttledErr *throttledError
+	var serviceErr *cloudflare.ServiceError
+	var requestErr *cloudflare.RequestError
+
+	// cloudflare-go names its errors after the HTTP status text:
+	// AuthorizationError is a 401, AuthenticationError a 403.
+	switch {
+	case errors.As(err, &authorizationErr):
+		return resource.OperationErrorCodeInvalidCredentials
+	case errors.As(err, &authenticationErr):
+		if hasErrorCode(authenticationErr, cfErrInvalidAccessToken) {
+			return resource.OperationErrorCodeInvalidCredentials
+		}
+		return resource.OperationErrorCodeAccessDenied
+	case errors.As(err, &notFoundErr):
+		return resource.OperationErrorCodeNotFound
+	case errors.As(err, &ratelimitErr), errors.As(err, &throttledErr):
+		return resource.OperationErrorCodeThrottling
+	case errors.As(err, &serviceErr):
+		return resource.OperationErrorCodeServiceInternalError
+	case errors.As(err, &requestErr):
+		return requestErrorCode(requestErr)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return resource.OperationErrorCodeServiceTimeout
+	case errors.Is(err, context.Canceled):
+		return resource.OperationErrorCodeInternalFailure
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return resource.OperationErrorCodeServiceTimeout
+		}
+		return resource.OperationErrorCodeNetworkFailure
+	}
+
+	if code, ok := retryExhaustedErrorCode(err); ok {
+		return code
+	}
+
+	return resource.OperationErrorCodeInternalFailure
+}
+
+// requestErrorCode maps a 4xx response not covered by a more specific
+// cloudflare-go error type, using the Cloudflare error codes it carries.
+func requestErrorCode(err *cloudflare.RequestError) resource.OperationErrorCode {
+	switch {
+	case hasErrorCode(err, cfErrIdenticalRecord, cfErrRecordExists, cfErrCNAMEExists, cfErrCNAMEConflict):
+		return resource.OperationErrorCodeAlreadyExists
+	case hasErrorCode(err, cfErrRecordNotFound, cfErrInvalidZone):
+		return resource.OperationErrorCodeNotFound
+	case hasErrorCode(err, cfErrRateLimited):
+		return resource.OperationErrorCodeThrottling
+	case hasErrorCode(err, cfErrInvalidHeaders, cfErrInvalidAuthFormat, cfErrInvalidAccessToken):
+		return resource.OperationErrorCodeInvalidCredentials
+	case hasErrorCode(err, cfErrAuthentication):
+		return resource.OperationErrorCodeAccessDenied
+	}
+	return resource.OperationErrorCodeInvalidRequest
+}
+
+// retryExhaustedErrorCode classifies the untyped error cloudflare-go returns
+// after giving up on 429 and 5xx responses.
+func retryExhaustedErrorCode(err error) (resource.OperationErrorCode, bool) {
+	msg := err.Error()
+	if strings.Contains(msg, "exceeded available rate limit retries") {
+		return resource.OperationErrorCodeThrottling, true
+	}
+	if m := cfRetryExhaustedPattern.FindStringSubmatch(msg); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		if status >= 500 {
+			return resource.OperationErrorCodeServiceInternalError, true
+		}
+	}
+	return "", false
+}
+
+// isIdenticalRecordError reports whether Cloudflare rejected a record because
+// a record with the same type, name and content exists.
+func isIdenticalRecordError(err error) bool {
+	var requestErr *cloudflare.RequestError
+	return errors.As(err, &requestErr) && hasErrorCode(requestErr, cfErrIdenticalRecord, cfErrRecordExists)
+}
+
+// hasErrorCode reports whether a Cloudflare error carries any of the given
+// Cloudflare error codes.
+func hasErrorCode(err cloudflareError, codes ...int) bool {
+	for _, got := range err.ErrorCodes() {
+		for _, want := range codes {
+			if got == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// Operation Errors
+// =============================================================================
+
+// operationError is returned as a Go error by operations whose result cannot
+// carry an error code and message, such as List. The error code leads the
+// message so formae can tell transient failures (e.g. Throttling) apart.
+type operationError struct {
+	code    resource.OperationErrorCode
+	message string
+}
+
+func (e *operationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+// listFailure builds the error returned by a failed List.
+func listFailure(config *TargetConfig, code resource.OperationErrorCode, format string, args ...any) error {
+	return &operationError{code: code, message: redactSecrets(config, fmt.Sprintf(format, args...))}
+}
+
+// redactedPlaceholder replaces secrets in messages.
+const redactedPlaceholder = "[REDACTED]"
+
+// bearerTokenPattern matches bearer tokens, e.g. from an echoed Authorization header.
+var bearerTokenPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+
+// redactSecrets removes the API token and proxy credentials from a message
+// before it is reported. config may be nil if it could not be parsed.
+func redactSecrets(config *TargetConfig, message string) string {
+	if config != nil {
+		if config.APIToken != "" {
+			message = strings.ReplaceAll(message, config.APIToken, redactedPlaceholder)
+		}
+		if proxyURL, err := url.Parse(config.ProxyURL); err == nil && proxyURL.User != nil {
+			if password, ok := proxyURL.User.Password(); ok && password != "" {
+				message = strings.ReplaceAll(message, password, redactedPlaceholder)
+			}
+		}
+	}
+	return bearerTokenPattern.ReplaceAllString(message, "${1}"+redactedPlaceholder)
+}
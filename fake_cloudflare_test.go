@@ -0,0 +1,794 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// =============================================================================
+// Fake Cloudflare API
+// =============================================================================
+//
+// fakeCloudflare is a stateful, in-process stand-in for the subset of the
+// Cloudflare v4 API used by the plugin: zone lookup and the DNS records
+// endpoints. Responses use the same envelope and error codes as the real API
+// so the plugin's error handling is exercised end to end.
+
+// Cloudflare API error codes returned by the fake.
+const (
+	cfCodeAuthentication    = 10000
+	cfCodeInvalidToken      = 9109
+	cfCodeInvalidZone       = 7003
+	cfCodeValidation        = 1004
+	cfCodeRecordNotFound    = 81044
+	cfCodeCNAMEConflict     = 81053
+	cfCodeCNAMEExists       = 81054
+	cfCodeIdenticalRecord   = 81058
+	cfCodeInvalidRecordType = 9000
+)
+
+const (
+	fakeDefaultPerPage     = 100
+	fakeMaxRecordsPerPage  = 5000
+	fakeMaxZonesPerPage    = 50
+	fakeDefaultZonePerPage = 20
+)
+
+// fakeZone is a zone known to the fake API.
+type fakeZone struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AccountID string `json:"-"`
+}
+
+// fakeRecord is a DNS record stored by the fake API, serialized the way the
+// Cloudflare API returns it.
+type fakeRecord struct {
+	ID         string         `json:"id"`
+	ZoneID     string         `json:"zone_id"`
+	ZoneName   string         `json:"zone_name"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Content    string         `json:"content"`
+	Proxiable  bool           `json:"proxiable"`
+	Proxied    bool           `json:"proxied"`
+	TTL        int            `json:"ttl"`
+	Priority   *uint16        `json:"priority,omitempty"`
+	Comment    *string        `json:"comment"`
+	Tags       []string       `json:"tags"`
+	Data       map[string]any `json:"data,omitempty"`
+	Settings   map[string]any `json:"settings"`
+	Meta       map[string]any `json:"meta,omitempty"`
+	CreatedOn  time.Time      `json:"created_on"`
+	ModifiedOn time.Time      `json:"modified_on"`
+}
+
+// fakeFailure is an injected failure returned instead of the next response,
+// or the next response of route if set. A dropped request is applied before
+// the connection is closed without a response.
+type fakeFailure struct {
+	route  string
+	drop   bool
+	status int
+	header http.Header
+	errors []cloudflare.ResponseInfo
+}
+
+type fakeCloudflare struct {
+	t      *testing.T
+	server *httptest.Server
+	token  string
+
+	mu       sync.Mutex
+	nextID   int
+	zones    []*fakeZone
+	records  map[string][]*fakeRecord // zone ID -> records in creation order
+	failures []fakeFailure
+	headers  http.Header           // sent with every response
+	calls    map[string]int        // "METHOD route" -> count
+	queries  map[string]url.Values // "METHOD route" -> query of the last request
+}
+
+// newFakeCloudflare starts a fake Cloudflare API that accepts the given token.
+// The server is shut down when the test completes.
+func newFakeCloudflare(t *testing.T, token string) *fakeCloudflare {
+	t.Helper()
+
+	f := newUnstartedFakeCloudflare(t, token)
+	f.server.Start()
+	return f
+}
+
+// newFakeCloudflareTLS is like newFakeCloudflare but serves HTTPS using a
+// self-signed certificate, available as PEM via caBundle.
+func newFakeCloudflareTLS(t *testing.T, token string) *fakeCloudflare {
+	t.Helper()
+
+	f := newUnstartedFakeCloudflare(t, token)
+	f.server.StartTLS()
+	return f
+}
+
+func newUnstartedFakeCloudflare(t *testing.T, token string) *fakeCloudflare {
+	f := &fakeCloudflare{
+		t:       t,
+		token:   token,
+		records: make(map[string][]*fakeRecord),
+		calls:   make(map[string]int),
+		queries: make(map[string]url.Values),
+	}
+	f.server = httptest.NewUnstartedServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// URL returns the base URL of the fake API, suitable for cloudflare.BaseURL.
+func (f *fakeCloudflare) URL() string {
+	return f.server.URL
+}
+
+// caBundle returns the PEM-encoded certificate of a TLS fake.
+func (f *fakeCloudflare) caBundle() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.server.Certificate().Raw}))
+}
+
+// clientOptions returns the Cloudflare client options that route requests to
+// the fake API without client-side rate limiting or retry delays.
+func (f *fakeCloudflare) clientOptions() []cloudflare.Option {
+	return []cloudflare.Option{
+		cloudflare.BaseURL(f.URL()),
+		cloudflare.UsingRateLimit(1000),
+		cloudflare.UsingRetryPolicy(0, 0, 0),
+	}
+}
+
+// plugin returns a Plugin wired to the fake API.
+func (f *fakeCloudflare) plugin() *Plugin {
+	return &Plugin{clientOptions: f.clientOptions(), limiters: rateLimiters{defaultRPS: 1000}}
+}
+
+// addZone registers a zone and returns its ID.
+func (f *fakeCloudflare) addZone(name string) string {
+	return f.addAccountZone("", name)
+}
+
+// addAccountZone registers a zone owned by the given account and returns its ID.
+func (f *fakeCloudflare) addAccountZone(accountID, name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	zone := &fakeZone{ID: f.newID(), Name: strings.ToLower(name), AccountID: accountID}
+	f.zones = append(f.zones, zone)
+	return zone.ID
+}
+
+// addRecord stores a record directly, bypassing the API, and returns its ID.
+// The record's name is expanded to a FQDN the same way the API does.
+func (f *fakeCloudflare) addRecord(zoneID string, record fakeRecord) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	zone := f.zoneByID(zoneID)
+	if zone == nil {
+		f.t.Fatalf("fake cloudflare: unknown zone %q", zoneID)
+	}
+
+	r := record
+	r.ID = f.newID()
+	r.ZoneID = zone.ID
+	r.ZoneName = zone.Name
+	r.Name = fakeFQDN(r.Name, zone.Name)
+	r.Proxiable = fakeProxiable(r.Type)
+	if r.TTL == 0 {
+		r.TTL = 1
+	}
+	if r.Settings == nil {
+		r.Settings = map[string]any{}
+	}
+	r.CreatedOn = time.Now().UTC()
+	r.ModifiedOn = r.CreatedOn
+	f.records[zone.ID] = append(f.records[zone.ID], &r)
+
+	return r.ID
+}
+
+// record returns a copy of the stored record, or nil if it does not exist.
+func (f *fakeCloudflare) record(zoneID, recordID string) *fakeRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range f.records[zoneID] {
+		if r.ID == recordID {
+			c := *r
+			return &c
+		}
+	}
+	return nil
+}
+
+// findRecords returns copies of the stored records with the given type and
+// name, which may be relative to the zone.
+func (f *fakeCloudflare) findRecords(zoneID, recordType, name string) []fakeRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	zone := f.zoneByID(zoneID)
+	var found []fakeRecord
+	for _, r := range f.records[zoneID] {
+		if r.Type == recordType && r.Name == fakeFQDN(name, zone.Name) {
+			found = append(found, *r)
+		}
+	}
+	return found
+}
+
+// recordCount returns the number of records stored in a zone.
+func (f *fakeCloudflare) recordCount(zoneID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.records[zoneID])
+}
+
+// failNext makes the next request fail with the given status and Cloudflare
+// error code. Multiple calls queue multiple failures.
+func (f *fakeCloudflare) failNext(status, code int, message string) {
+	f.failNextWithHeader(status, nil, code, message)
+}
+
+// failNextWithHeader is like failNext but also sets response headers,
+// e.g. Retry-After.
+func (f *fakeCloudflare) failNextWithHeader(status int, header http.Header, code int, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, fakeFailure{
+		status: status,
+		header: header,
+		errors: []cloudflare.ResponseInfo{{Code: code, Message: message}},
+	})
+}
+
+// failNextOn is like failNextWithHeader but only fails the next request to a
+// route, e.g. "POST /zones/{zone_id}/dns_records".
+func (f *fakeCloudflare) failNextOn(route string, status int, header http.Header, code int, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, fakeFailure{
+		route:  route,
+		status: status,
+		header: header,
+		errors: []cloudflare.ResponseInfo{{Code: code, Message: message}},
+	})
+}
+
+// dropNextOn makes the next request to a route take effect, but closes the
+// connection before the response is sent, as if the network failed after the
+// request reached Cloudflare.
+func (f *fakeCloudflare) dropNextOn(route string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures = append(f.failures, fakeFailure{route: route, drop: true})
+}
+
+// setResponseHeader sets a header sent with every response, e.g. Ratelimit.
+func (f *fakeCloudflare) setResponseHeader(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.headers == nil {
+		f.headers = make(http.Header)
+	}
+	f.headers.Set(key, value)
+}
+
+// callCount returns how many requests were made to a route, e.g.
+// "GET /zones/{zone_id}" or "POST /zones/{zone_id}/dns_records".
+func (f *fakeCloudflare) callCount(route string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[route]
+}
+
+// lastQuery returns the query parameters of the last request to a route.
+func (f *fakeCloudflare) lastQuery(route string) url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.queries[route]
+}
+
+// =============================================================================
+// Request Handling
+// =============================================================================
+
+func (f *fakeCloudflare) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := r.Method + " " + fakeRoute(parts)
+	f.calls[route]++
+	f.queries[route] = r.URL.Query()
+
+	for k, v := range f.headers {
+		w.Header()[k] = v
+	}
+
+	drop := false
+	for i, failure := range f.failures {
+		if failure.route != "" && failure.route != route {
+			continue
+		}
+		f.failures = append(f.failures[:i], f.failures[i+1:]...)
+		if failure.drop {
+			drop = true
+			break
+		}
+		for k, v := range failure.header {
+			w.Header()[k] = v
+		}
+		writeFakeError(w, failure.status, failure.errors...)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		writeFakeError(w, http.StatusForbidden, cloudflare.ResponseInfo{Code: cfCodeInvalidToken, Message: "Invalid access token"})
+		return
+	}
+
+	if drop {
+		f.dispatch(httptest.NewRecorder(), r, parts)
+		panic(http.ErrAbortHandler)
+	}
+	f.dispatch(w, r, parts)
+}
+
+// dispatch routes an authenticated request to its handler.
+func (f *fakeCloudflare) dispatch(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 1 && parts[0] == "zones" && r.Method == http.MethodGet:
+		f.listZones(w, r)
+	case len(parts) == 2 && parts[0] == "zones" && r.Method == http.MethodGet:
+		f.getZone(w, parts[1])
+	case len(parts) == 3 && parts[0] == "zones" && parts[2] == "dns_records":
+		zone := f.zoneByID(parts[1])
+		if zone == nil {
+			writeFakeError(w, http.StatusNotFound, fakeInvalidZone(r.URL.Path))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			f.listRecords(w, r, zone)
+		case http.MethodPost:
+			f.createRecord(w, r, zone)
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, cloudflare.ResponseInfo{Code: 10405, Message: "Method not allowed"})
+		}
+	case len(parts) == 4 && parts[0] == "zones" && parts[2] == "dns_records":
+		zone := f.zoneByID(parts[1])
+		if zone == nil {
+			writeFakeError(w, http.StatusNotFound, fakeInvalidZone(r.URL.Path))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			f.getRecord(w, zone, parts[3])
+		case http.MethodPatch, http.MethodPut:
+			f.updateRecord(w, r, zone, parts[3])
+		case http.MethodDelete:
+			f.deleteRecord(w, zone, parts[3])
+		default:
+			writeFakeError(w, http.StatusMethodNotAllowed, cloudflare.ResponseInfo{Code: 10405, Message: "Method not allowed"})
+		}
+	default:
+		writeFakeError(w, http.StatusNotFound, cloudflare.ResponseInfo{Code: 7000, Message: "No route for that URI"})
+	}
+}
+
+func (f *fakeCloudflare) listZones(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := strings.ToLower(query.Get("name"))
+	accountID := query.Get("account.id")
+
+	var matched []any
+	for _, z := range f.zones {
+		if name != "" && z.Name != name {
+			continue
+		}
+		if accountID != "" && z.AccountID != accountID {
+			continue
+		}
+		matched = append(matched, z)
+	}
+
+	writeFakePage(w, r, matched, fakeDefaultZonePerPage, fakeMaxZonesPerPage)
+}
+
+func (f *fakeCloudflare) getZone(w http.ResponseWriter, zoneID string) {
+	zone := f.zoneByID(zoneID)
+	if zone == nil {
+		writeFakeError(w, http.StatusNotFound, fakeInvalidZone("/zones/"+zoneID))
+		return
+	}
+	writeFakeResult(w, http.StatusOK, zone)
+}
+
+func (f *fakeCloudflare) listRecords(w http.ResponseWriter, r *http.Request, zone *fakeZone) {
+	query := r.URL.Query()
+	recordType := strings.ToUpper(query.Get("type"))
+	name := query.Get("name")
+	nameSuffix := strings.ToLower(query.Get("name.endswith"))
+	content := query.Get("content")
+
+	var matched []any
+	for _, rec := range f.records[zone.ID] {
+		if recordType != "" && rec.Type != recordType {
+			continue
+		}
+		if name != "" && rec.Name != fakeFQDN(name, zone.Name) {
+			continue
+		}
+		if nameSuffix != "" && !strings.HasSuffix(rec.Name, nameSuffix) {
+			continue
+		}
+		if content != "" && rec.Content != content {
+			continue
+		}
+		matched = append(matched, rec)
+	}
+
+	writeFakePage(w, r, matched, fakeDefaultPerPage, fakeMaxRecordsPerPage)
+}
+
+func (f *fakeCloudflare) getRecord(w http.ResponseWriter, zone *fakeZone, recordID string) {
+	rec := f.recordByID(zone.ID, recordID)
+	if rec == nil {
+		writeFakeError(w, http.StatusNotFound, cloudflare.ResponseInfo{Code: cfCodeRecordNotFound, Message: "Record does not exist."})
+		return
+	}
+	writeFakeResult(w, http.StatusOK, rec)
+}
+
+func (f *fakeCloudflare) createRecord(w http.ResponseWriter, r *http.Request, zone *fakeZone) {
+	fields, ok := decodeFakeBody(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	rec := &fakeRecord{
+		ID:         f.newID(),
+		ZoneID:     zone.ID,
+		ZoneName:   zone.Name,
+		TTL:        1,
+		Settings:   map[string]any{},
+		CreatedOn:  now,
+		ModifiedOn: now,
+	}
+	if errInfo := applyFakeFields(rec, fields); errInfo != nil {
+		writeFakeError(w, http.StatusBadRequest, *errInfo)
+		return
+	}
+	rec.Name = fakeFQDN(rec.Name, zone.Name)
+	rec.Proxiable = fakeProxiable(rec.Type)
+
+	if errInfo := f.checkRecord(zone, rec); errInfo != nil {
+		writeFakeError(w, http.StatusBadRequest, *errInfo)
+		return
+	}
+
+	f.records[zone.ID] = append(f.records[zone.ID], rec)
+	writeFakeResult(w, http.StatusOK, rec)
+}
+
+func (f *fakeCloudflare) updateRecord(w http.ResponseWriter, r *http.Request, zone *fakeZone, recordID string) {
+	existing := f.recordByID(zone.ID, recordID)
+	if existing == nil {
+		writeFakeError(w, http.StatusNotFound, cloudflare.ResponseInfo{Code: cfCodeRecordNotFound, Message: "Record does not exist."})
+		return
+	}
+
+	fields, ok := decodeFakeBody(w, r)
+	if !ok {
+		return
+	}
+
+	updated := *existing
+	if errInfo := applyFakeFields(&updated, fields); errInfo != nil {
+		writeFakeError(w, http.StatusBadRequest, *errInfo)
+		return
+	}
+	updated.Name = fakeFQDN(updated.Name, zone.Name)
+	updated.Proxiable = fakeProxiable(updated.Type)
+	updated.ModifiedOn = time.Now().UTC()
+
+	if errInfo := f.checkRecord(zone, &updated); errInfo != nil {
+		writeFakeError(w, http.StatusBadRequest, *errInfo)
+		return
+	}
+
+	*existing = updated
+	writeFakeResult(w, http.StatusOK, existing)
+}
+
+func (f *fakeCloudflare) deleteRecord(w http.ResponseWriter, zone *fakeZone, recordID string) {
+	records := f.records[zone.ID]
+	for i, rec := range records {
+		if rec.ID == recordID {
+			f.records[zone.ID] = append(records[:i:i], records[i+1:]...)
+			writeFakeResult(w, http.StatusOK, map[string]string{"id": recordID})
+			return
+		}
+	}
+	writeFakeError(w, http.StatusNotFound, cloudflare.ResponseInfo{Code: cfCodeRecordNotFound, Message: "Record does not exist."})
+}
+
+// checkRecord applies the API-side validation rules the plugin relies on:
+// required fields, proxy eligibility, identical records and CNAME exclusivity.
+func (f *fakeCloudflare) checkRecord(zone *fakeZone, rec *fakeRecord) *cloudflare.ResponseInfo {
+	if rec.Type == "" {
+		return &cloudflare.ResponseInfo{Code: cfCodeInvalidRecordType, Message: "DNS Validation Error: type is required."}
+	}
+	if rec.Name == "" {
+		return &cloudflare.ResponseInfo{Code: cfCodeValidation, Message: "DNS Validation Error: name is required."}
+	}
+	if rec.Content == "" && rec.Data == nil {
+		return &cloudflare.ResponseInfo{Code: cfCodeValidation, Message: "DNS Validation Error: content is required."}
+	}
+	if rec.Proxied && !rec.Proxiable {
+		return &cloudflare.ResponseInfo{Code: cfCodeValidation, Message: fmt.Sprintf("DNS Validation Error: %s records cannot be proxied.", rec.Type)}
+	}
+
+	for _, other := range f.records[zone.ID] {
+		if other.ID == rec.ID || other.Name != rec.Name {
+			continue
+		}
+		if other.Type == rec.Type && other.Content == rec.Content {
+			return &cloudflare.ResponseInfo{Code: cfCodeIdenticalRecord, Message: "An identical record already exists."}
+		}
+		if other.Type == "CNAME" {
+			return &cloudflare.ResponseInfo{Code: cfCodeCNAMEExists, Message: "A CNAME record with that host already exists."}
+		}
+		if rec.Type == "CNAME" {
+			return &cloudflare.ResponseInfo{Code: cfCodeCNAMEConflict, Message: "An A, AAAA, or CNAME record with that host already exists."}
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+// Helpers
+// =============================================================================
+
+func (f *fakeCloudflare) newID() string {
+	f.nextID++
+	return fmt.Sprintf("%032x", f.nextID)
+}
+
+func (f *fakeCloudflare) zoneByID(zoneID string) *fakeZone {
+	for _, z := range f.zones {
+		if z.ID == zoneID {
+			return z
+		}
+	}
+	return nil
+}
+
+func (f *fakeCloudflare) recordByID(zoneID, recordID string) *fakeRecord {
+	for _, r := range f.records[zoneID] {
+		if r.ID == recordID {
+			return r
+		}
+	}
+	return nil
+}
+
+// fakeRoute maps path segments to a route template used for call counting.
+func fakeRoute(parts []string) string {
+	templated := make([]string, len(parts))
+	for i, p := range parts {
+		switch {
+		case i == 1 && parts[0] == "zones":
+			templated[i] = "{zone_id}"
+		case i == 3 && parts[2] == "dns_records":
+			templated[i] = "{record_id}"
+		default:
+			templated[i] = p
+		}
+	}
+	return "/" + strings.Join(templated, "/")
+}
+
+// fakeFQDN expands a record name to the fully qualified, lowercase form the
+// API stores: "@" and "" map to the apex, relative names get the zone appended.
+func fakeFQDN(name, zoneName string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" || name == "@" {
+		return zoneName
+	}
+	if name == zoneName || strings.HasSuffix(name, "."+zoneName) {
+		return name
+	}
+	return name + "." + zoneName
+}
+
+func fakeProxiable(recordType string) bool {
+	return recordType == "A" || recordType == "AAAA" || recordType == "CNAME"
+}
+
+func fakeInvalidZone(path string) cloudflare.ResponseInfo {
+	return cloudflare.ResponseInfo{
+		Code:    cfCodeInvalidZone,
+		Message: fmt.Sprintf("Could not route to %s, perhaps your object identifier is invalid?", path),
+	}
+}
+
+func decodeFakeBody(w http.ResponseWriter, r *http.Request) (map[string]json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeFakeError(w, http.StatusBadRequest, cloudflare.ResponseInfo{Code: 9207, Message: "Request body is invalid."})
+		return nil, false
+	}
+	return fields, true
+}
+
+// applyFakeFields applies the JSON fields of a create/update request body to
+// a record. Fields absent from the body are left unchanged.
+func applyFakeFields(rec *fakeRecord, fields map[string]json.RawMessage) *cloudflare.ResponseInfo {
+	// Sort keys so validation errors are deterministic.
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := fields[key]
+		var err error
+		switch key {
+		case "type":
+			err = json.Unmarshal(raw, &rec.Type)
+			rec.Type = strings.ToUpper(rec.Type)
+		case "name":
+			err = json.Unmarshal(raw, &rec.Name)
+		case "content":
+			err = json.Unmarshal(raw, &rec.Content)
+		case "ttl":
+			err = json.Unmarshal(raw, &rec.TTL)
+		case "proxied":
+			err = json.Unmarshal(raw, &rec.Proxied)
+		case "priority":
+			rec.Priority = nil
+			err = json.Unmarshal(raw, &rec.Priority)
+		case "comment":
+			var comment *string
+			err = json.Unmarshal(raw, &comment)
+			if comment != nil && *comment == "" {
+				comment = nil
+			}
+			rec.Comment = comment
+		case "tags":
+			rec.Tags = nil
+			err = json.Unmarshal(raw, &rec.Tags)
+		case "data":
+			rec.Data = nil
+			err = json.Unmarshal(raw, &rec.Data)
+		case "settings":
+			var settings map[string]any
+			err = json.Unmarshal(raw, &settings)
+			merged := make(map[string]any, len(rec.Settings)+len(settings))
+			for k, v := range rec.Settings {
+				merged[k] = v
+			}
+			for k, v := range settings {
+				merged[k] = v
+			}
+			rec.Settings = merged
+		}
+		if err != nil {
+			return &cloudflare.ResponseInfo{Code: cfCodeValidation, Message: fmt.Sprintf("DNS Validation Error: invalid %s.", key)}
+		}
+	}
+
+	if rec.TTL != 1 && (rec.TTL < 30 || rec.TTL > 86400) {
+		return &cloudflare.ResponseInfo{Code: cfCodeValidation, Message: "DNS Validation Error: TTL must be between 30 and 86400 seconds, or 1 for Automatic."}
+	}
+
+	return nil
+}
+
+// writeFakePage writes one page of results using the page/per_page query
+// parameters, mirroring the API's result_info block.
+func writeFakePage(w http.ResponseWriter, r *http.Request, items []any, defaultPerPage, maxPerPage int) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	total := len(items)
+	totalPages := (total + perPage - 1) / perPage
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	result := items[start:end]
+	if result == nil {
+		result = []any{}
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]any{
+		"success":  true,
+		"errors":   []any{},
+		"messages": []any{},
+		"result":   result,
+		"result_info": map[string]int{
+			"page":        page,
+			"per_page":    perPage,
+			"count":       len(result),
+			"total_count": total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+func writeFakeResult(w http.ResponseWriter, status int, result any) {
+	writeFakeJSON(w, status, map[string]any{
+		"success":  true,
+		"errors":   []any{},
+		"messages": []any{},
+		"result":   result,
+	})
+}
+
+func writeFakeError(w http.ResponseWriter, status int, errs ...cloudflare.ResponseInfo) {
+	writeFakeJSON(w, status, map[string]any{
+		"success":  false,
+		"errors":   errs,
+		"messages": []any{},
+		"result":   nil,
+	})
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -6,6 +6,8 @@ require (
 	github.com/cloudflare/cloudflare-go v0.116.0
 	github.com/platform-engineering-labs/formae/pkg/plugin v0.1.7
 	github.com/platform-engineering-labs/formae/pkg/plugin-conformance-tests v0.1.9
+	golang.org/x/net v0.47.0
+	golang.org/x/time v0.9.0
 )
 
 require (
@@ -39,10 +41,8 @@ require (
 	go.opentelemetry.io/otel/sdk/metric v1.39.0 // indirect
 	go.opentelemetry.io/otel/trace v1.39.0 // indirect
 	go.opentelemetry.io/proto/otlp v1.9.0 // indirect
-	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
-	golang.org/x/time v0.9.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20251202230838-ff82c1b0f217 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
 	google.golang.org/grpc v1.77.0 // indirect
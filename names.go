@@ -0,0 +1,184 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// apexName is the canonical name of a record at the zone apex.
+const apexName = "@"
+
+// Record names are compared in one canonical form on both the write and the
+// read path: relative to the zone, lowercase, without a trailing dot, with
+// internationalized labels in Unicode, and "@" for the apex. "www", "WWW",
+// "www.example.com" and "www.example.com." all become "www" in zone
+// example.com. Like Cloudflare, names ending in the zone name are taken as
+// fully qualified. Names are sent to Cloudflare fully qualified and in
+// A-labels (punycode).
+//
+// A name whose relative form would itself end in the zone name, such as
+// "www.example.com.example.com", keeps its absolute form with a trailing dot,
+// as the relative form would be taken as fully qualified on the way back.
+
+// idnaProfile converts internationalized labels between Unicode and A-labels.
+// It folds case and rejects code points IDNA 2008 does not allow.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.BidiRule())
+
+// canonicalName returns the canonical form of a record name. Without a zone
+// name, only case, the trailing dot and internationalized labels are
+// normalized.
+func canonicalName(name, zoneName string) string {
+	name = foldName(name)
+	zoneName = foldName(zoneName)
+
+	switch {
+	case name == "" || name == apexName:
+		return apexName
+	case zoneName == "":
+		return unicodeName(name)
+	case name == zoneName:
+		return apexName
+	}
+
+	relative := strings.TrimSuffix(name, "."+zoneName)
+	if relative != name && (relative == zoneName || strings.HasSuffix(relative, "."+zoneName)) {
+		return unicodeName(name) + "."
+	}
+	return unicodeName(relative)
+}
+
+// recordFQDN returns the fully qualified name of a record in A-labels,
+// without a trailing dot, as sent to Cloudflare.
+func recordFQDN(name, zoneName string) string {
+	canonical := canonicalName(name, zoneName)
+	name = foldName(canonical)
+	zoneName = foldName(zoneName)
+	switch {
+	case zoneName == "" || strings.HasSuffix(canonical, "."):
+		return name
+	case name == apexName:
+		return zoneName
+	}
+	return name + "." + zoneName
+}
+
+// foldName returns a name in lowercase A-labels without a trailing dot.
+// Labels that cannot be converted are only lowercased; validateName reports
+// them on the write path.
+func foldName(name string) string {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if ascii, err := asciiName(name); err == nil {
+		return ascii
+	}
+	return strings.ToLower(name)
+}
+
+// validateName checks that the internationalized labels of a name only use
+// code points allowed by IDNA 2008.
+func validateName(name string) error {
+	_, err := asciiName(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	return err
+}
+
+// asciiName converts the Unicode labels of a name to lowercase A-labels.
+// ASCII labels are only lowercased, so service labels ("_sip") and the
+// wildcard ("*"), which IDNA does not allow, pass unchanged.
+func asciiName(name string) (string, error) {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			labels[i] = strings.ToLower(label)
+			continue
+		}
+		ascii, err := idnaProfile.ToASCII(label)
+		if err == nil {
+			err = checkIDNARunes(ascii)
+		}
+		if err != nil {
+			return "", fmt.Errorf("label %q is not a valid internationalized label: %w", label, err)
+		}
+		labels[i] = ascii
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// unicodeName converts the A-labels of a name to Unicode. Labels that are not
+// valid A-labels are kept as they are.
+func unicodeName(name string) string {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(label, "xn--") {
+			continue
+		}
+		if unicode, err := idnaProfile.ToUnicode(label); err == nil {
+			labels[i] = unicode
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// contextualRunes are the non-letter code points IDNA 2008 permits in
+// specific contexts (RFC 5892, appendix A).
+var contextualRunes = map[rune]bool{
+	'\u00b7': true, // MIDDLE DOT
+	'\u0375': true, // GREEK LOWER NUMERAL SIGN
+	'\u05f3': true, // HEBREW PUNCTUATION GERESH
+	'\u05f4': true, // HEBREW PUNCTUATION GERSHAYIM
+	'\u200c': true, // ZERO WIDTH NON-JOINER
+	'\u200d': true, // ZERO WIDTH JOINER
+	'\u30fb': true, // KATAKANA MIDDLE DOT
+}
+
+// checkIDNARunes rejects A-labels that decode to symbols or punctuation. The
+// idna package implements UTS #46, which still maps these, while IDNA 2008
+// disallows them.
+func checkIDNARunes(ascii string) error {
+	label, err := idnaProfile.ToUnicode(ascii)
+	if err != nil {
+		return err
+	}
+	for _, r := range label {
+		switch {
+		case r == '-', unicode.IsLetter(r), unicode.IsMark(r), unicode.IsDigit(r), contextualRunes[r]:
+		default:
+			return fmt.Errorf("code point %U is not allowed", r)
+		}
+	}
+	return nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// setCanonicalName rewrites the record name relative to its zone, replaces
+// hostname content "@" with the zone name, and checks structured data that is
+// derived from the name again, such as the name of SRV records, which may be
+// given relative to the zone.
+func setCanonicalName(props *DNSRecordProperties, zoneName string) error {
+	props.Name = canonicalName(props.Name, zoneName)
+	props.zoneName = zoneName
+	if hostnameRecordTypes[props.RecordType] && props.Content == apexName {
+		props.Content = foldName(zoneName)
+	}
+	if len(props.Data) == 0 {
+		return nil
+	}
+
+	// The data is canonical at this point and the content derived from it,
+	// so the content is derived again.
+	props.Content = ""
+	return normalizeRecordData(props)
+}
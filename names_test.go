@@ -0,0 +1,127 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		name     string
+		zoneName string
+		expected string
+	}{
+		{"www", "example.com", "www"},
+		{"WWW", "example.com", "www"},
+		{"www.example.com", "example.com", "www"},
+		{"www.Example.COM.", "example.com", "www"},
+		{"a.b.example.com", "example.com", "a.b"},
+		{"@", "example.com", "@"},
+		{"", "example.com", "@"},
+		{"example.com", "example.com", "@"},
+		{"Example.com.", "example.com.", "@"},
+		{"www.example.org", "example.com", "www.example.org"},
+		{"notexample.com", "example.com", "notexample.com"},
+		{"XN--BCHER-KVA", "example.com", "bücher"},
+		{"Bücher.example.com", "example.com", "bücher"},
+		{"www.xn--mnchen-3ya.de", "münchen.de", "www"},
+		{"_sip._tcp.Bücher", "example.com", "_sip._tcp.bücher"},
+		{"*.example.com", "example.com", "*"},
+		{"www.example.com.", "", "www.example.com"},
+		{"www.example.com.example.com", "example.com", "www.example.com.example.com."},
+		{"Example.com.example.com.", "example.com", "example.com.example.com."},
+		{"www.example.com.example.com.", "example.com", "www.example.com.example.com."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalName(tt.name, tt.zoneName); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRecordFQDN(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"www", "www.example.com"},
+		{"www.example.com.", "www.example.com"},
+		{"@", "example.com"},
+		{"EXAMPLE.com", "example.com"},
+		{"www.example.com.example.com.", "www.example.com.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recordFQDN(tt.name, "example.com"); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRecordToProperties_NameRoundTrips(t *testing.T) {
+	names := []string{
+		"example.com",
+		"www.example.com",
+		"a.b.example.com",
+		"*.example.com",
+		"_sip._tcp.example.com",
+		"xn--bcher-kva.example.com",
+		"example.com.example.com",
+		"www.example.com.example.com",
+		"www.xn--bcher-kva.example.com.example.com",
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			record := cloudflare.DNSRecord{Type: "A", Name: name, Content: "192.0.2.1"}
+			props := recordToProperties(record, "example.com")
+			if got := recordFQDN(props.Name, "example.com"); got != name {
+				t.Errorf("expected %q to round-trip through %q, got %q", name, props.Name, got)
+			}
+			if got := canonicalName(props.Name, "example.com"); got != props.Name {
+				t.Errorf("expected canonical name %q to be kept, got %q", props.Name, got)
+			}
+		})
+	}
+}
+
+func TestSetCanonicalName_RebuildsSRVData(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "SRV",
+		Name:       "_sip._tcp.example.com.",
+		Content:    "5 5060 sip.example.com",
+		TTL:        1,
+		Priority:   intPtr(10),
+	}
+	if err := validateProperties(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := setCanonicalName(props, "example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Name != "_sip._tcp" {
+		t.Errorf("expected Name '_sip._tcp', got '%s'", props.Name)
+	}
+
+	var data srvData
+	if err := json.Unmarshal(props.Data, &data); err != nil {
+		t.Fatalf("failed to parse data: %v", err)
+	}
+	if data.Name != "@" {
+		t.Errorf("expected data name '@', got '%s'", data.Name)
+	}
+}
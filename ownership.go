@@ -0,0 +1,94 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// Records created with a resource label carry an ownership marker at the end
+// of their comment, e.g. "web server [formae:3f2a9c0b17d4e865]". The marker
+// is derived from the target's owner_id, the label, the record type and the
+// fully qualified name, so a retried Create finds the record an earlier
+// attempt created and returns it instead of creating a duplicate. Stacks
+// using the same label for a record of the same type and name share the
+// marker, so a marked record is only returned if its content matches too;
+// otherwise a new record is created next to it. Read strips
+// the marker, so comments round-trip as declared. Comments are used rather
+// than tags as they are available on all Cloudflare plans.
+
+// markerPrefix starts every ownership marker.
+const markerPrefix = "formae:"
+
+// markerLen is the length a marker adds to a comment, e.g.
+// " [formae:3f2a9c0b17d4e865]".
+const markerLen = len(" ["+markerPrefix+"]") + 16
+
+// markerPattern matches an ownership marker at the end of a comment.
+var markerPattern = regexp.MustCompile(`\s*\[(formae:[0-9a-f]{16})\]$`)
+
+// ownershipMarker returns the marker for a record created under label, or ""
+// if there is no label. The name must be canonical.
+func ownershipMarker(config *TargetConfig, label string, props *DNSRecordProperties, zoneName string) string {
+	if label == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{config.OwnerID, label, props.RecordType, recordFQDN(props.Name, zoneName)}, "\x00")))
+	return markerPrefix + hex.EncodeToString(sum[:8])
+}
+
+// markComment appends a marker to a comment.
+func markComment(comment, marker string) string {
+	switch {
+	case marker == "":
+		return comment
+	case comment == "":
+		return "[" + marker + "]"
+	}
+	return comment + " [" + marker + "]"
+}
+
+// splitComment splits a comment into the declared comment and its marker.
+func splitComment(comment string) (string, string) {
+	m := markerPattern.FindStringSubmatchIndex(comment)
+	if m == nil {
+		return comment, ""
+	}
+	return comment[:m[0]], comment[m[2]:m[3]]
+}
+
+// apiComment returns the comment sent to Cloudflare, or nil to leave it
+// unchanged.
+func apiComment(props *DNSRecordProperties) *string {
+	if props.Comment == nil && props.marker == "" {
+		return nil
+	}
+	comment := ""
+	if props.Comment != nil {
+		comment = *props.Comment
+	}
+	comment = markComment(comment, props.marker)
+	return &comment
+}
+
+// findMarkedRecord returns the record among records with the type, name and
+// content of props that carries its ownership marker, or nil if there is
+// none.
+func findMarkedRecord(props *DNSRecordProperties, records []cloudflare.DNSRecord, zoneName string) *cloudflare.DNSRecord {
+	if props.marker == "" {
+		return nil
+	}
+	for _, record := range records {
+		if _, marker := splitComment(record.Comment); marker == props.marker && isSameRecord(record, props, zoneName) {
+			return &record
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOwnershipMarker(t *testing.T) {
+	config := &TargetConfig{OwnerID: "prod"}
+	props := &DNSRecordProperties{RecordType: "A", Name: "www"}
+
+	marker := ownershipMarker(config, "web", props, "example.com")
+	if !markerPattern.MatchString("[" + marker + "]") {
+		t.Fatalf("expected a marker matching %s, got %q", markerPattern, marker)
+	}
+	if again := ownershipMarker(config, "web", props, "example.com"); again != marker {
+		t.Errorf("expected a deterministic marker, got %q and %q", marker, again)
+	}
+
+	variants := map[string]string{
+		"owner": ownershipMarker(&TargetConfig{OwnerID: "staging"}, "web", props, "example.com"),
+		"label": ownershipMarker(config, "api", props, "example.com"),
+		"type":  ownershipMarker(config, "web", &DNSRecordProperties{RecordType: "AAAA", Name: "www"}, "example.com"),
+		"name":  ownershipMarker(config, "web", &DNSRecordProperties{RecordType: "A", Name: "www2"}, "example.com"),
+	}
+	for field, variant := range variants {
+		if variant == marker {
+			t.Errorf("expected a different marker for a different %s", field)
+		}
+	}
+
+	if unlabeled := ownershipMarker(config, "", props, "example.com"); unlabeled != "" {
+		t.Errorf("expected no marker without a label, got %q", unlabeled)
+	}
+}
+
+func TestSplitComment(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    string
+		marker  string
+	}{
+		{"web server [formae:3f2a9c0b17d4e865]", "web server", "formae:3f2a9c0b17d4e865"},
+		{"[formae:3f2a9c0b17d4e865]", "", "formae:3f2a9c0b17d4e865"},
+		{"web server", "web server", ""},
+		{"[formae:3f2a9c0b17d4e865] web server", "[formae:3f2a9c0b17d4e865] web server", ""},
+		{"web server [formae:xyz]", "web server [formae:xyz]", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			comment, marker := splitComment(tt.comment)
+			if comment != tt.want || marker != tt.marker {
+				t.Errorf("expected (%q, %q), got (%q, %q)", tt.want, tt.marker, comment, marker)
+			}
+		})
+	}
+}
+
+func TestMarkComment_RoundTrips(t *testing.T) {
+	for _, comment := range []string{"", "web server"} {
+		marked := markComment(comment, "formae:3f2a9c0b17d4e865")
+		if !strings.HasSuffix(marked, "[formae:3f2a9c0b17d4e865]") {
+			t.Errorf("expected marker at the end, got %q", marked)
+		}
+		if got, _ := splitComment(marked); got != comment {
+			t.Errorf("expected comment %q after splitting, got %q", comment, got)
+		}
+	}
+}
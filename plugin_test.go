@@ -0,0 +1,1945 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/platform-engineering-labs/formae/pkg/plugin"
+	"github.com/platform-engineering-labs/formae/pkg/plugin/resource"
+)
+
+const (
+	testAPIToken = "test-token-123"
+	testZoneName = "example.com"
+)
+
+// newTestPlugin starts a fake Cloudflare API with a single zone and returns a
+// plugin wired to it together with the matching target config.
+func newTestPlugin(t *testing.T) (*Plugin, *fakeCloudflare, string, json.RawMessage) {
+	t.Helper()
+
+	fake := newFakeCloudflare(t, testAPIToken)
+	zoneID := fake.addZone(testZoneName)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q}`, testAPIToken, zoneID))
+
+	return fake.plugin(), fake, zoneID, config
+}
+
+func createRecord(t *testing.T, p *Plugin, config json.RawMessage, props string) string {
+	t.Helper()
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(props),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("create failed: %s (%s)", result.ProgressResult.StatusMessage, result.ProgressResult.ErrorCode)
+	}
+	if result.ProgressResult.NativeID == "" {
+		t.Fatal("expected NativeID to be set")
+	}
+
+	return result.ProgressResult.NativeID
+}
+
+func updateRecord(t *testing.T, p *Plugin, config json.RawMessage, nativeID, props string) *resource.ProgressResult {
+	t.Helper()
+
+	result, err := p.Update(context.Background(), &resource.UpdateRequest{
+		NativeID:          nativeID,
+		ResourceType:      "CLOUDFLARE::DNS::Record",
+		DesiredProperties: json.RawMessage(props),
+		TargetConfig:      config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return result.ProgressResult
+}
+
+func readRecord(t *testing.T, p *Plugin, config json.RawMessage, nativeID string) (*resource.ReadResult, *DNSRecordProperties) {
+	t.Helper()
+
+	result, err := p.Read(context.Background(), &resource.ReadRequest{
+		NativeID:     nativeID,
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ErrorCode != "" {
+		return result, nil
+	}
+
+	var props DNSRecordProperties
+	if err := json.Unmarshal([]byte(result.Properties), &props); err != nil {
+		t.Fatalf("failed to parse read properties: %v", err)
+	}
+
+	return result, &props
+}
+
+// primeZoneName makes the plugin resolve and cache the zone name, so failures
+// injected afterwards hit the record request instead of the zone lookup.
+func primeZoneName(t *testing.T, p *Plugin, config json.RawMessage) {
+	t.Helper()
+
+	readRecord(t, p, config, "00000000000000000000000000000000")
+}
+
+// =============================================================================
+// CRUD Lifecycle Tests
+// =============================================================================
+
+func TestPlugin_CRUDLifecycle(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	ctx := context.Background()
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "A",
+		"name": "www",
+		"content": "192.0.2.1",
+		"ttl": 300,
+		"proxied": true
+	}`)
+
+	stored := fake.record(zoneID, nativeID)
+	if stored == nil {
+		t.Fatal("expected record to exist in fake API")
+	}
+	if stored.Name != "www.example.com" {
+		t.Errorf("expected stored name 'www.example.com', got '%s'", stored.Name)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Name != "www" {
+		t.Errorf("expected Name 'www', got '%s'", props.Name)
+	}
+	if props.Content != "192.0.2.1" {
+		t.Errorf("expected Content '192.0.2.1', got '%s'", props.Content)
+	}
+	if props.TTL != 300 {
+		t.Errorf("expected TTL 300, got %d", props.TTL)
+	}
+	if !props.Proxied {
+		t.Error("expected Proxied true, got false")
+	}
+
+	updateResult, err := p.Update(ctx, &resource.UpdateRequest{
+		NativeID:          nativeID,
+		ResourceType:      "CLOUDFLARE::DNS::Record",
+		DesiredProperties: json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.2", "ttl": 600, "comment": "updated"}`),
+		TargetConfig:      config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updateResult.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("update failed: %s", updateResult.ProgressResult.StatusMessage)
+	}
+
+	_, props = readRecord(t, p, config, nativeID)
+	if props.Content != "192.0.2.2" {
+		t.Errorf("expected Content '192.0.2.2', got '%s'", props.Content)
+	}
+	if props.TTL != 600 {
+		t.Errorf("expected TTL 600, got %d", props.TTL)
+	}
+	if props.Proxied {
+		t.Error("expected Proxied false after update, got true")
+	}
+	if props.Comment == nil || *props.Comment != "updated" {
+		t.Error("expected Comment 'updated'")
+	}
+
+	deleteResult, err := p.Delete(ctx, &resource.DeleteRequest{
+		NativeID:     nativeID,
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleteResult.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("delete failed: %s", deleteResult.ProgressResult.StatusMessage)
+	}
+	if fake.recordCount(zoneID) != 0 {
+		t.Errorf("expected zone to be empty after delete, got %d records", fake.recordCount(zoneID))
+	}
+
+	result, _ := readRecord(t, p, config, nativeID)
+	if result.ErrorCode != resource.OperationErrorCodeNotFound {
+		t.Errorf("expected NotFound after delete, got '%s'", result.ErrorCode)
+	}
+}
+
+func TestPlugin_ReadApexRecord(t *testing.T) {
+	p, _, _, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "MX",
+		"name": "@",
+		"content": "mail.example.com",
+		"priority": 10
+	}`)
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Name != "@" {
+		t.Errorf("expected Name '@', got '%s'", props.Name)
+	}
+	if props.Priority == nil || *props.Priority != 10 {
+		t.Error("expected Priority 10")
+	}
+}
+
+func TestPlugin_NameFormsAreCanonical(t *testing.T) {
+	for _, name := range []string{"www", "WWW", "www.example.com", "www.Example.com."} {
+		t.Run(name, func(t *testing.T) {
+			p, fake, zoneID, config := newTestPlugin(t)
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(fmt.Sprintf(`{"record_type": "A", "name": %q, "content": "192.0.2.1"}`, name)),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			nativeID := result.ProgressResult.NativeID
+
+			if stored := fake.record(zoneID, nativeID); stored.Name != "www.example.com" {
+				t.Errorf("expected stored name 'www.example.com', got '%s'", stored.Name)
+			}
+
+			var created DNSRecordProperties
+			if err := json.Unmarshal(result.ProgressResult.ResourceProperties, &created); err != nil {
+				t.Fatalf("failed to parse resource properties: %v", err)
+			}
+			if created.Name != "www" {
+				t.Errorf("expected created Name 'www', got '%s'", created.Name)
+			}
+
+			_, props := readRecord(t, p, config, nativeID)
+			if props.Name != "www" {
+				t.Errorf("expected read Name 'www', got '%s'", props.Name)
+			}
+		})
+	}
+}
+
+func TestPlugin_ApexNameForms(t *testing.T) {
+	for _, name := range []string{"@", "example.com", "EXAMPLE.COM."} {
+		t.Run(name, func(t *testing.T) {
+			p, fake, zoneID, config := newTestPlugin(t)
+
+			nativeID := createRecord(t, p, config, fmt.Sprintf(`{"record_type": "TXT", "name": %q, "content": "v=spf1 -all"}`, name))
+
+			if stored := fake.record(zoneID, nativeID); stored.Name != "example.com" {
+				t.Errorf("expected stored name 'example.com', got '%s'", stored.Name)
+			}
+			_, props := readRecord(t, p, config, nativeID)
+			if props.Name != "@" {
+				t.Errorf("expected Name '@', got '%s'", props.Name)
+			}
+		})
+	}
+}
+
+func TestPlugin_InternationalizedNames(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{"record_type": "CNAME", "name": "Bücher", "content": "shop.München.de"}`)
+
+	stored := fake.record(zoneID, nativeID)
+	if stored.Name != "xn--bcher-kva.example.com" {
+		t.Errorf("expected A-label name to be sent, got '%s'", stored.Name)
+	}
+	if stored.Content != "shop.xn--mnchen-3ya.de" {
+		t.Errorf("expected A-label content to be sent, got '%s'", stored.Content)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Name != "bücher" {
+		t.Errorf("expected Name 'bücher', got '%s'", props.Name)
+	}
+	if props.Content != "shop.münchen.de" {
+		t.Errorf("expected Content 'shop.münchen.de', got '%s'", props.Content)
+	}
+}
+
+func TestPlugin_LongTXTRecordRoundTrips(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	key := "v=DKIM1; k=rsa; p=" + strings.Repeat("MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8A", 12)
+
+	nativeID := createRecord(t, p, config, fmt.Sprintf(`{"record_type": "TXT", "name": "selector1._domainkey", "content": %q}`, key))
+
+	stored := fake.record(zoneID, nativeID)
+	strs, ok := parseTXTStrings(stored.Content)
+	if !ok || len(strs) != 2 || len(strs[0]) != 255 {
+		t.Errorf("expected content split into quoted strings of 255 bytes, got %q", stored.Content)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Content != key {
+		t.Errorf("expected the declared value, got %q", props.Content)
+	}
+}
+
+func TestPlugin_QuotedTXTRecordIsUnquoted(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: "@", Content: `"v=spf1 include:_spf.example.com " "-all"`})
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Content != "v=spf1 include:_spf.example.com -all" {
+		t.Errorf("expected joined value, got %q", props.Content)
+	}
+}
+
+func TestPlugin_RecordTags(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "A",
+		"name": "api",
+		"content": "192.0.2.1",
+		"tags": [{"key": "team", "value": "payments"}, {"key": "cost-center", "value": "cc-42"}, {"key": "pci"}]
+	}`)
+
+	stored := fake.record(zoneID, nativeID)
+	if !slices.Equal(stored.Tags, []string{"cost-center:cc-42", "pci", "team:payments"}) {
+		t.Errorf("expected tags in key:value form, got %v", stored.Tags)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	expected := []DNSRecordTag{{Key: "cost-center", Value: "cc-42"}, {Key: "pci"}, {Key: "team", Value: "payments"}}
+	if !slices.Equal(props.Tags, expected) {
+		t.Errorf("expected tags %v, got %v", expected, props.Tags)
+	}
+
+	result := updateRecord(t, p, config, nativeID, `{"record_type": "A", "name": "api", "content": "192.0.2.1"}`)
+	if result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("update failed: %s", result.StatusMessage)
+	}
+	if stored := fake.record(zoneID, nativeID); len(stored.Tags) != 0 {
+		t.Errorf("expected tags to be cleared, got %v", stored.Tags)
+	}
+}
+
+func TestPlugin_RecordSettings(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "CNAME",
+		"name": "@",
+		"content": "app.example.net",
+		"settings": {"flatten_cname": true}
+	}`)
+
+	if stored := fake.record(zoneID, nativeID); stored.Settings["flatten_cname"] != true {
+		t.Errorf("expected flatten_cname to be sent, got %v", stored.Settings)
+	}
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Settings == nil || !props.Settings.FlattenCNAME {
+		t.Errorf("expected flatten_cname to be reported, got %+v", props.Settings)
+	}
+
+	result := updateRecord(t, p, config, nativeID, `{"record_type": "CNAME", "name": "@", "content": "app.example.net", "proxied": true, "settings": {"ipv6_only": true}}`)
+	if result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("update failed: %s", result.StatusMessage)
+	}
+	stored := fake.record(zoneID, nativeID)
+	if stored.Settings["flatten_cname"] != false || stored.Settings["ipv6_only"] != true {
+		t.Errorf("expected flatten_cname cleared and ipv6_only set, got %v", stored.Settings)
+	}
+}
+
+func TestPlugin_DashboardSettingsAreReported(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1", Proxied: true, Settings: map[string]any{"ipv4_only": true}})
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Settings == nil || !props.Settings.IPv4Only || props.Settings.IPv6Only {
+		t.Errorf("expected ipv4_only to be reported, got %+v", props.Settings)
+	}
+}
+
+func TestPlugin_SettingsRejectedLocally(t *testing.T) {
+	p, fake, _, config := newTestPlugin(t)
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "MX", "name": "@", "content": "mail.example.com", "priority": 10, "settings": {"flatten_cname": true}}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+		t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 0 {
+		t.Errorf("expected no API call, got %d", n)
+	}
+}
+
+func TestPlugin_HTTPSRecordWithData(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "HTTPS",
+		"name": "www",
+		"data": {"priority": 1, "target": ".", "alpn": ["h3", "h2"], "ipv4hint": ["192.0.2.1"]}
+	}`)
+
+	stored := fake.record(zoneID, nativeID)
+	if stored.Content != `1 . alpn="h3,h2" ipv4hint="192.0.2.1"` {
+		t.Errorf("expected canonical content to be sent, got %q", stored.Content)
+	}
+	if stored.Data["value"] != `alpn="h3,h2" ipv4hint="192.0.2.1"` {
+		t.Errorf("expected SvcParams in data, got %v", stored.Data)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Content != stored.Content {
+		t.Errorf("expected Content %q, got %q", stored.Content, props.Content)
+	}
+	expected := `{"priority":1,"target":".","alpn":["h3","h2"],"ipv4hint":["192.0.2.1"]}`
+	if string(props.Data) != expected {
+		t.Errorf("expected Data %s, got %s", expected, props.Data)
+	}
+	if props.Priority != nil {
+		t.Errorf("expected no top-level Priority, got %d", *props.Priority)
+	}
+}
+
+func TestPlugin_SRVRecordWithData(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "SRV",
+		"name": "_sip._tcp",
+		"data": {"priority": 10, "weight": 5, "port": 5060, "target": "sipserver.example.com"}
+	}`)
+
+	stored := fake.record(zoneID, nativeID)
+	if stored.Content != "5 5060 sipserver.example.com" {
+		t.Errorf("expected content derived from data, got %q", stored.Content)
+	}
+	if stored.Priority == nil || *stored.Priority != 10 {
+		t.Error("expected priority 10 to be sent")
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Priority == nil || *props.Priority != 10 {
+		t.Error("expected Priority 10")
+	}
+	expected := `{"service":"_sip","proto":"_tcp","name":"@","priority":10,"weight":5,"port":5060,"target":"sipserver.example.com"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected Data %s, got %s", expected, props.Data)
+	}
+}
+
+func TestPlugin_CAARecordWithOtherTag(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "CAA", Name: "example.com", Content: `0 issuemail "ca.example"`})
+
+	_, props := readRecord(t, p, config, nativeID)
+	expected := `{"flags":0,"tag":"issuemail","value":"ca.example"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected Data %s, got %s", expected, props.Data)
+	}
+
+	result := updateRecord(t, p, config, nativeID, `{"record_type": "CAA", "name": "@", "data": {"flags": 0, "tag": "issuemail", "value": "ca.example.net"}}`)
+	if result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("update failed: %s", result.StatusMessage)
+	}
+}
+
+func TestPlugin_SRVRecordWithFullyQualifiedName(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataName string // JSON field, empty to omit it
+		wantErr  bool
+	}{
+		{"apex", `"name": "@", `, false},
+		{"fully qualified", `"name": "Example.com.", `, false},
+		{"omitted", ``, false},
+		{"other name", `"name": "eu", `, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, zoneID, config := newTestPlugin(t)
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties: json.RawMessage(`{
+					"record_type": "SRV",
+					"name": "_sip._tcp.example.com",
+					"data": {` + tt.dataName + `"priority": 10, "weight": 5, "port": 5060, "target": "sipserver.example.com"}
+				}`),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+					t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+				}
+				if n := fake.recordCount(zoneID); n != 0 {
+					t.Errorf("expected no record to be created, got %d", n)
+				}
+				return
+			}
+			if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+				t.Fatalf("create failed: %s", result.ProgressResult.StatusMessage)
+			}
+
+			// The data written and read back must agree, so the plan does not drift
+			_, props := readRecord(t, p, config, result.ProgressResult.NativeID)
+			expected := `{"service":"_sip","proto":"_tcp","name":"@","priority":10,"weight":5,"port":5060,"target":"sipserver.example.com"}`
+			if string(props.Data) != expected {
+				t.Errorf("expected Data %s, got %s", expected, props.Data)
+			}
+			var written DNSRecordProperties
+			if err := json.Unmarshal(result.ProgressResult.ResourceProperties, &written); err != nil {
+				t.Fatalf("failed to parse written properties: %v", err)
+			}
+			if string(written.Data) != expected {
+				t.Errorf("expected written Data %s, got %s", expected, written.Data)
+			}
+		})
+	}
+}
+
+func TestPlugin_HostnameContentWithoutTrailingDot(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{"record_type": "CNAME", "name": "docs", "content": "Docs.Example.net."}`)
+	if stored := fake.record(zoneID, nativeID); stored.Content != "docs.example.net" {
+		t.Errorf("expected content without trailing dot to be sent, got %q", stored.Content)
+	}
+
+	dottedID := fake.addRecord(zoneID, fakeRecord{Type: "NS", Name: "dev", Content: "ns1.example.net."})
+	_, props := readRecord(t, p, config, dottedID)
+	if props.Content != "ns1.example.net" {
+		t.Errorf("expected content without trailing dot, got %q", props.Content)
+	}
+}
+
+func TestPlugin_CAARecordReadCanonical(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "CAA", Name: "example.com", Content: "0 ISSUE letsencrypt.org"})
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Content != `0 issue "letsencrypt.org"` {
+		t.Errorf("expected canonical Content, got %q", props.Content)
+	}
+	expected := `{"flags":0,"tag":"issue","value":"letsencrypt.org"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected Data %s, got %s", expected, props.Data)
+	}
+}
+
+func TestPlugin_TLSARecordWithData(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, fmt.Sprintf(`{
+		"record_type": "TLSA",
+		"name": "_443._tcp.www",
+		"data": {"usage": 3, "selector": 1, "matching_type": 1, "certificate": %q}
+	}`, strings.ToUpper(testSHA256)))
+
+	stored := fake.record(zoneID, nativeID)
+	if stored.Content != "3 1 1 "+testSHA256 {
+		t.Errorf("expected canonical content to be sent, got %q", stored.Content)
+	}
+	if stored.Data["certificate"] != testSHA256 {
+		t.Errorf("expected certificate in data, got %v", stored.Data)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	expected := `{"usage":3,"selector":1,"matching_type":1,"certificate":"` + testSHA256 + `"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected Data %s, got %s", expected, props.Data)
+	}
+}
+
+func TestPlugin_URIRecordWithData(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{
+		"record_type": "URI",
+		"name": "_ftp._tcp",
+		"data": {"priority": 10, "weight": 1, "target": "ftp://ftp.example.com/public"}
+	}`)
+
+	stored := fake.record(zoneID, nativeID)
+	if stored.Priority == nil || *stored.Priority != 10 {
+		t.Error("expected priority 10 to be sent")
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	expected := `{"priority":10,"weight":1,"target":"ftp://ftp.example.com/public"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected Data %s, got %s", expected, props.Data)
+	}
+}
+
+func TestPlugin_DiscoveredLOCRecordIsReadable(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "LOC", Name: "office", Content: "51 30 12.748 N 0 7 39.611 W 0.00m 1m 10000m 10m"})
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Content != "51 30 12.748 N 0 7 39.611 W 0.00m 1.00m 10000.00m 10.00m" {
+		t.Errorf("expected canonical Content, got %q", props.Content)
+	}
+	if len(props.Data) == 0 {
+		t.Error("expected Data to be reported")
+	}
+}
+
+func TestPlugin_HTTPSRecordInvalidData(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "HTTPS", "name": "www", "data": {"priority": 0, "target": ".", "port": 443}}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+		t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if fake.recordCount(zoneID) != 0 {
+		t.Error("expected no record to be created")
+	}
+}
+
+func TestPlugin_InvalidContentRejectedLocally(t *testing.T) {
+	p, fake, _, config := newTestPlugin(t)
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "AAAA", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+		t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if !strings.Contains(result.ProgressResult.StatusMessage, "must be an IPv6 address") {
+		t.Errorf("expected a precise message, got %q", result.ProgressResult.StatusMessage)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 0 {
+		t.Errorf("expected no API call, got %d", n)
+	}
+}
+
+func TestPlugin_DeleteMissingRecordSucceeds(t *testing.T) {
+	p, _, _, config := newTestPlugin(t)
+
+	result, err := p.Delete(context.Background(), &resource.DeleteRequest{
+		NativeID:     "00000000000000000000000000000000",
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Errorf("expected success deleting a missing record, got %s", result.ProgressResult.OperationStatus)
+	}
+}
+
+func TestPlugin_CreateRejectedByAPI(t *testing.T) {
+	p, fake, _, config := newTestPlugin(t)
+
+	primeZoneName(t, p, config)
+	fake.failNext(http.StatusBadRequest, cfCodeValidation, "DNS Validation Error")
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusFailure {
+		t.Fatalf("expected failure, got %s", result.ProgressResult.OperationStatus)
+	}
+	if result.ProgressResult.StatusMessage == "" {
+		t.Error("expected StatusMessage to be set")
+	}
+}
+
+func TestPlugin_CreateReportsIdenticalRecord(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	existingID := fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: "example.com", Content: `"v=spf1 -all"`})
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "TXT", "name": "@", "content": "v=spf1 -all"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeAlreadyExists {
+		t.Errorf("expected AlreadyExists, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if !strings.Contains(result.ProgressResult.StatusMessage, existingID) {
+		t.Errorf("expected message to name record %s, got %q", existingID, result.ProgressResult.StatusMessage)
+	}
+}
+
+func TestPlugin_CreateAdoptsIdenticalRecord(t *testing.T) {
+	tests := []struct {
+		name         string
+		targetOption string
+		recordOption string
+		adopted      bool
+	}{
+		{"target option", `, "adopt_existing": true`, "", true},
+		{"record option", "", `, "adopt_existing": true`, true},
+		{"record overrides target", `, "adopt_existing": true`, `, "adopt_existing": false`, false},
+		{"not set", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, zoneID, _ := newTestPlugin(t)
+			config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q%s}`, testAPIToken, zoneID, tt.targetOption))
+			existingID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www.example.com", Content: "192.0.2.1", TTL: 3600})
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(fmt.Sprintf(`{"record_type": "A", "name": "www", "content": "192.0.2.1", "ttl": 300, "comment": "managed"%s}`, tt.recordOption)),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !tt.adopted {
+				if result.ProgressResult.ErrorCode != resource.OperationErrorCodeAlreadyExists {
+					t.Errorf("expected AlreadyExists, got '%s'", result.ProgressResult.ErrorCode)
+				}
+				return
+			}
+			if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+				t.Fatalf("expected adoption, got %s: %s", result.ProgressResult.OperationStatus, result.ProgressResult.StatusMessage)
+			}
+			if result.ProgressResult.NativeID != existingID {
+				t.Errorf("expected NativeID %s, got %s", existingID, result.ProgressResult.NativeID)
+			}
+			stored := fake.record(zoneID, existingID)
+			if stored.TTL != 300 || stored.Comment == nil || *stored.Comment != "managed" {
+				t.Errorf("expected adopted record to be updated, got TTL %d, comment %v", stored.TTL, stored.Comment)
+			}
+		})
+	}
+}
+
+func TestPlugin_CreateAdoptsIdenticalIDNRecord(t *testing.T) {
+	p, fake, zoneID, _ := newTestPlugin(t)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "adopt_existing": true}`, testAPIToken, zoneID))
+	existingID := fake.addRecord(zoneID, fakeRecord{Type: "CNAME", Name: "www", Content: "xn--bcher-kva.example"})
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "CNAME", "name": "www", "content": "bücher.example", "comment": "managed"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("expected adoption, got %s: %s", result.ProgressResult.ErrorCode, result.ProgressResult.StatusMessage)
+	}
+	if result.ProgressResult.NativeID != existingID {
+		t.Errorf("expected NativeID %s, got %s", existingID, result.ProgressResult.NativeID)
+	}
+	if n := fake.recordCount(zoneID); n != 1 {
+		t.Errorf("expected one record, got %d", n)
+	}
+}
+
+func TestPlugin_RetriedCreateReturnsMarkedRecord(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	create := func() *resource.ProgressResult {
+		result, err := p.Create(context.Background(), &resource.CreateRequest{
+			ResourceType: "CLOUDFLARE::DNS::Record",
+			Label:        "web",
+			Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1", "comment": "web server"}`),
+			TargetConfig: config,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+			t.Fatalf("create failed: %s", result.ProgressResult.StatusMessage)
+		}
+		return result.ProgressResult
+	}
+
+	first := create()
+	stored := fake.record(zoneID, first.NativeID)
+	if stored.Comment == nil || !markerPattern.MatchString(*stored.Comment) || !strings.HasPrefix(*stored.Comment, "web server [") {
+		t.Errorf("expected comment with ownership marker, got %v", stored.Comment)
+	}
+
+	// A retry after a lost response must not create a second record
+	second := create()
+	if second.NativeID != first.NativeID {
+		t.Errorf("expected retried create to return %s, got %s", first.NativeID, second.NativeID)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 1 {
+		t.Errorf("expected one record to be created, got %d", n)
+	}
+
+	_, props := readRecord(t, p, config, first.NativeID)
+	if props.Comment == nil || *props.Comment != "web server" {
+		t.Errorf("expected comment without marker, got %v", props.Comment)
+	}
+}
+
+func TestPlugin_CreateRetriedByTransportReturnsMarkedRecord(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	// The first POST creates the record, but its response is lost and the
+	// transport sends the request again
+	primeZoneName(t, p, config)
+	fake.dropNextOn("POST /zones/{zone_id}/dns_records")
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Label:        "web",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("create failed: %s (%s)", result.ProgressResult.ErrorCode, result.ProgressResult.StatusMessage)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 2 {
+		t.Errorf("expected the create to be sent twice, got %d", n)
+	}
+	if n := fake.recordCount(zoneID); n != 1 {
+		t.Errorf("expected one record, got %d", n)
+	}
+	if fake.record(zoneID, result.ProgressResult.NativeID) == nil {
+		t.Errorf("expected the created record %s to be returned", result.ProgressResult.NativeID)
+	}
+}
+
+func TestPlugin_CreateDoesNotReuseMarkedRecordWithOtherContent(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	create := func(content string) string {
+		result, err := p.Create(context.Background(), &resource.CreateRequest{
+			ResourceType: "CLOUDFLARE::DNS::Record",
+			Label:        "web",
+			Properties:   json.RawMessage(fmt.Sprintf(`{"record_type": "A", "name": "www", "content": %q}`, content)),
+			TargetConfig: config,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+			t.Fatalf("create failed: %s (%s)", result.ProgressResult.ErrorCode, result.ProgressResult.StatusMessage)
+		}
+		return result.ProgressResult.NativeID
+	}
+
+	// Two stacks declare the same label, type and name, so their records
+	// carry the same marker
+	first := create("192.0.2.1")
+	second := create("192.0.2.2")
+
+	if second == first {
+		t.Fatalf("expected a new record, got the marked record %s", first)
+	}
+	if stored := fake.record(zoneID, first); stored.Content != "192.0.2.1" {
+		t.Errorf("expected the first record to keep its content, got %s", stored.Content)
+	}
+	if n := fake.recordCount(zoneID); n != 2 {
+		t.Errorf("expected two records, got %d", n)
+	}
+}
+
+func TestPlugin_CreateWithoutMarkerIsNotResent(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	// Without a label there is no marker to find the record by, so a POST
+	// whose response is lost is not sent again
+	primeZoneName(t, p, config)
+	fake.dropNextOn("POST /zones/{zone_id}/dns_records")
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeNetworkFailure {
+		t.Errorf("expected NetworkFailure, got '%s' (%s)", result.ProgressResult.ErrorCode, result.ProgressResult.StatusMessage)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 1 {
+		t.Errorf("expected the create to be sent once, got %d", n)
+	}
+	if n := fake.recordCount(zoneID); n != 1 {
+		t.Errorf("expected one record, got %d", n)
+	}
+}
+
+// newRegistryTestPlugin returns a plugin whose target enables the TXT
+// registry with owner ID "prod".
+func newRegistryTestPlugin(t *testing.T) (*Plugin, *fakeCloudflare, string, json.RawMessage) {
+	t.Helper()
+
+	p, fake, zoneID, _ := newTestPlugin(t)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "registry": "txt", "owner_id": "prod"}`, testAPIToken, zoneID))
+	return p, fake, zoneID, config
+}
+
+func deleteRecord(t *testing.T, p *Plugin, config json.RawMessage, nativeID string) *resource.ProgressResult {
+	t.Helper()
+
+	result, err := p.Delete(context.Background(), &resource.DeleteRequest{
+		NativeID:     nativeID,
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result.ProgressResult
+}
+
+func TestPlugin_RegistryClaimsAndReleases(t *testing.T) {
+	p, fake, zoneID, config := newRegistryTestPlugin(t)
+
+	first := createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+	second := createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.2"}`)
+
+	companions := fake.findRecords(zoneID, "TXT", "_formae-owner.a.www")
+	if len(companions) != 1 || txtValue(companions[0].Content) != "heritage=formae,formae/owner=prod" {
+		t.Fatalf("expected one ownership record, got %v", companions)
+	}
+
+	_, props := readRecord(t, p, config, first)
+	if props.Owner == nil || *props.Owner != "prod" {
+		t.Errorf("expected owner 'prod', got %v", props.Owner)
+	}
+
+	if result := deleteRecord(t, p, config, first); result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("delete failed: %s", result.StatusMessage)
+	}
+	if n := len(fake.findRecords(zoneID, "TXT", "_formae-owner.a.www")); n != 1 {
+		t.Errorf("expected ownership record to stay while a record remains, got %d", n)
+	}
+
+	if result := deleteRecord(t, p, config, second); result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("delete failed: %s", result.StatusMessage)
+	}
+	if n := len(fake.findRecords(zoneID, "TXT", "_formae-owner.a.www")); n != 0 {
+		t.Errorf("expected ownership record to be removed with the last record, got %d", n)
+	}
+}
+
+func TestPlugin_RegistryRefusesForeignRecords(t *testing.T) {
+	tests := []struct {
+		name      string
+		ownerName string
+		owner     string
+		content   string
+	}{
+		{"other installation", "_formae-owner.a.www", "staging", "heritage=formae,formae/owner=staging"},
+		{"external-dns", "a-www", "external-dns/default", `"heritage=external-dns,external-dns/owner=default,external-dns/resource=ingress/web/web"`},
+		{"external-dns legacy", "www", "external-dns/default", "heritage=external-dns,external-dns/owner=default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, zoneID, config := newRegistryTestPlugin(t)
+			fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: tt.ownerName, Content: tt.content})
+			nativeID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+			_, props := readRecord(t, p, config, nativeID)
+			if props.Owner == nil || *props.Owner != tt.owner {
+				t.Errorf("expected owner %q, got %v", tt.owner, props.Owner)
+			}
+
+			result := updateRecord(t, p, config, nativeID, `{"record_type": "A", "name": "www", "content": "192.0.2.9"}`)
+			if result.ErrorCode != resource.OperationErrorCodeResourceConflict {
+				t.Errorf("expected update to fail with ResourceConflict, got '%s'", result.ErrorCode)
+			}
+			if !strings.Contains(result.StatusMessage, tt.owner) {
+				t.Errorf("expected message to name owner %q, got %q", tt.owner, result.StatusMessage)
+			}
+
+			if result := deleteRecord(t, p, config, nativeID); result.ErrorCode != resource.OperationErrorCodeResourceConflict {
+				t.Errorf("expected delete to fail with ResourceConflict, got '%s'", result.ErrorCode)
+			}
+			if stored := fake.record(zoneID, nativeID); stored == nil || stored.Content != "192.0.2.1" {
+				t.Errorf("expected record to be left alone, got %+v", stored)
+			}
+
+			create, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.2"}`),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if create.ProgressResult.ErrorCode != resource.OperationErrorCodeResourceConflict {
+				t.Errorf("expected create to fail with ResourceConflict, got '%s'", create.ProgressResult.ErrorCode)
+			}
+		})
+	}
+}
+
+func TestPlugin_RegistryClaimsUnownedRecordOnUpdate(t *testing.T) {
+	p, fake, zoneID, config := newRegistryTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "CNAME", Name: "docs", Content: "docs.example.net"})
+
+	result := updateRecord(t, p, config, nativeID, `{"record_type": "CNAME", "name": "docs", "content": "docs.example.org"}`)
+	if result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("update failed: %s", result.StatusMessage)
+	}
+	if n := len(fake.findRecords(zoneID, "TXT", "_formae-owner.cname.docs")); n != 1 {
+		t.Errorf("expected update to claim the record, got %d ownership records", n)
+	}
+}
+
+func TestPlugin_ListSkipsOwnershipRecords(t *testing.T) {
+	p, fake, zoneID, config := newRegistryTestPlugin(t)
+	nativeID := createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+
+	result, err := p.List(context.Background(), &resource.ListRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.recordCount(zoneID) != 2 || len(result.NativeIDs) != 1 || result.NativeIDs[0] != nativeID {
+		t.Errorf("expected only %s to be discovered, got %v", nativeID, result.NativeIDs)
+	}
+}
+
+func TestPlugin_CreateRejectsConflictingRecords(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   fakeRecord
+		properties string
+	}{
+		{"CNAME next to A", fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"}, `{"record_type": "CNAME", "name": "www", "content": "web.example.net"}`},
+		{"TXT next to CNAME", fakeRecord{Type: "CNAME", Name: "docs", Content: "docs.example.net"}, `{"record_type": "TXT", "name": "docs", "content": "hello"}`},
+		{"second CNAME", fakeRecord{Type: "CNAME", Name: "docs", Content: "docs.example.net"}, `{"record_type": "CNAME", "name": "docs", "content": "docs.example.org"}`},
+		{"A below delegation", fakeRecord{Type: "NS", Name: "dev", Content: "ns1.example.net"}, `{"record_type": "A", "name": "app.dev", "content": "192.0.2.1"}`},
+		{"delegation above A", fakeRecord{Type: "A", Name: "app.dev", Content: "192.0.2.1"}, `{"record_type": "NS", "name": "dev", "content": "ns1.example.net"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, zoneID, config := newTestPlugin(t)
+			existingID := fake.addRecord(zoneID, tt.existing)
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(tt.properties),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+				t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+			}
+			if !strings.Contains(result.ProgressResult.StatusMessage, existingID) {
+				t.Errorf("expected message to name %s, got %q", existingID, result.ProgressResult.StatusMessage)
+			}
+			if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 0 {
+				t.Errorf("expected no create call, got %d", n)
+			}
+		})
+	}
+}
+
+func TestPlugin_CreateListsRecordsOnce(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties string
+		lastQuery  string
+	}{
+		{"record", `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`, "type=NS"},
+		{"delegation", `{"record_type": "NS", "name": "dev", "content": "ns1.example.net"}`, "name.endswith=.dev.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, zoneID, config := newTestPlugin(t)
+			fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "other", Content: "192.0.2.9"})
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Label:        "web",
+				Properties:   json.RawMessage(tt.properties),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+				t.Fatalf("create failed: %s", result.ProgressResult.StatusMessage)
+			}
+
+			// One list at the name, shared by the conflict, marker and
+			// identical record checks, and one for delegations
+			route := "GET /zones/{zone_id}/dns_records"
+			if n := fake.callCount(route); n != 2 {
+				t.Errorf("expected 2 list calls, got %d", n)
+			}
+			key, value, _ := strings.Cut(tt.lastQuery, "=")
+			if got := fake.lastQuery(route).Get(key); got != value {
+				t.Errorf("expected last list with %s, got %q", tt.lastQuery, fake.lastQuery(route).Encode())
+			}
+		})
+	}
+}
+
+func TestPlugin_CNAMEContentAtApex(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{"record_type": "CNAME", "name": "www", "content": "@"}`)
+	if stored := fake.record(zoneID, nativeID); stored.Content != testZoneName {
+		t.Errorf("expected content %q, got %q", testZoneName, stored.Content)
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Content != testZoneName {
+		t.Errorf("expected content to read back as %q, got %q", testZoneName, props.Content)
+	}
+}
+
+func TestPlugin_CreateRejectsUnflattenedApexCNAME(t *testing.T) {
+	p, fake, _, config := newTestPlugin(t)
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "CNAME", "name": "@", "content": "app.example.net"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+		t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 0 {
+		t.Errorf("expected no create call, got %d", n)
+	}
+}
+
+func TestPlugin_UpdateChecksConflictsWithOtherRecords(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "CNAME", Name: "docs", Content: "docs.example.net"})
+
+	result := updateRecord(t, p, config, nativeID, `{"record_type": "CNAME", "name": "docs", "content": "docs.example.org"}`)
+	if result.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("expected a CNAME not to conflict with itself: %s", result.StatusMessage)
+	}
+
+	otherID := fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: "docs", Content: "hello"})
+	result = updateRecord(t, p, config, nativeID, `{"record_type": "CNAME", "name": "docs", "content": "docs.example.com"}`)
+	if result.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+		t.Errorf("expected InvalidRequest, got '%s'", result.ErrorCode)
+	}
+	if !strings.Contains(result.StatusMessage, otherID) {
+		t.Errorf("expected message to name %s, got %q", otherID, result.StatusMessage)
+	}
+	if stored := fake.record(zoneID, nativeID); stored.Content != "docs.example.org" {
+		t.Errorf("expected record to be left alone, got %q", stored.Content)
+	}
+}
+
+func TestPlugin_InvalidToken(t *testing.T) {
+	p, _, zoneID, _ := newTestPlugin(t)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": "wrong-token", "zone_id": %q}`, zoneID))
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusFailure {
+		t.Fatalf("expected failure with wrong token, got %s", result.ProgressResult.OperationStatus)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidCredentials {
+		t.Errorf("expected InvalidCredentials, got '%s'", result.ProgressResult.ErrorCode)
+	}
+}
+
+func TestPlugin_APIErrorsClassified(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		code     int
+		message  string
+		expected resource.OperationErrorCode
+	}{
+		{"identical record", http.StatusBadRequest, cfCodeIdenticalRecord, "An identical record already exists.", resource.OperationErrorCodeAlreadyExists},
+		{"cname conflict", http.StatusBadRequest, cfCodeCNAMEConflict, "An A, AAAA, or CNAME record with that host already exists.", resource.OperationErrorCodeAlreadyExists},
+		{"validation", http.StatusBadRequest, cfCodeValidation, "DNS Validation Error", resource.OperationErrorCodeInvalidRequest},
+		{"missing permission", http.StatusForbidden, cfCodeAuthentication, "Authentication error", resource.OperationErrorCodeAccessDenied},
+		{"unauthorized", http.StatusUnauthorized, cfCodeInvalidToken, "Invalid access token", resource.OperationErrorCodeInvalidCredentials},
+		{"rate limited", http.StatusTooManyRequests, 971, "Please wait and consider throttling your request speed", resource.OperationErrorCodeThrottling},
+		{"server error", http.StatusBadGateway, 0, "Bad Gateway", resource.OperationErrorCodeServiceInternalError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, fake, zoneID, _ := newTestPlugin(t)
+			config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "retry_max_attempts": 1}`, testAPIToken, zoneID))
+			primeZoneName(t, p, config)
+			fake.failNext(tt.status, tt.code, tt.message)
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProgressResult.ErrorCode != tt.expected {
+				t.Errorf("expected %s, got '%s' (%s)", tt.expected, result.ProgressResult.ErrorCode, result.ProgressResult.StatusMessage)
+			}
+		})
+	}
+}
+
+func TestPlugin_NetworkFailure(t *testing.T) {
+	p, fake, zoneID, _ := newTestPlugin(t)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "retry_max_attempts": 1}`, testAPIToken, zoneID))
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+	fake.server.Close()
+
+	result, err := p.Read(context.Background(), &resource.ReadRequest{
+		NativeID:     nativeID,
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ErrorCode != resource.OperationErrorCodeNetworkFailure {
+		t.Errorf("expected NetworkFailure, got '%s'", result.ErrorCode)
+	}
+}
+
+// =============================================================================
+// Retry Tests
+// =============================================================================
+
+func TestPlugin_RetriesTransientErrors(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	primeZoneName(t, p, config)
+	retryNow := http.Header{"Retry-After": []string{"0"}}
+	fake.failNextOn("POST /zones/{zone_id}/dns_records", http.StatusTooManyRequests, retryNow, 971, "Please wait and consider throttling your request speed")
+	fake.failNextOn("POST /zones/{zone_id}/dns_records", http.StatusBadGateway, retryNow, 0, "Bad Gateway")
+
+	nativeID := createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+	if fake.record(zoneID, nativeID) == nil {
+		t.Fatal("expected record to be created after retries")
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 3 {
+		t.Errorf("expected 3 create attempts, got %d", n)
+	}
+}
+
+func TestPlugin_RetryAfterBeyondMaxDelay(t *testing.T) {
+	p, fake, zoneID, _ := newTestPlugin(t)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "retry_max_delay_seconds": 5}`, testAPIToken, zoneID))
+
+	primeZoneName(t, p, config)
+	fake.failNextOn("POST /zones/{zone_id}/dns_records", http.StatusTooManyRequests, http.Header{"Retry-After": []string{"60"}}, 971, "Please wait and consider throttling your request speed")
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeThrottling {
+		t.Errorf("expected Throttling, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if n := fake.callCount("POST /zones/{zone_id}/dns_records"); n != 1 {
+		t.Errorf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestPlugin_RetryRespectsDeadline(t *testing.T) {
+	p, fake, _, config := newTestPlugin(t)
+
+	primeZoneName(t, p, config)
+	fake.failNextWithHeader(http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"10"}}, 0, "Service Unavailable")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	result, err := p.Create(ctx, &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to give up without waiting past the deadline, took %s", elapsed)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeServiceInternalError {
+		t.Errorf("expected ServiceInternalError, got '%s'", result.ProgressResult.ErrorCode)
+	}
+}
+
+func TestPlugin_AdaptsToRateLimitHeaders(t *testing.T) {
+	p, fake, _, config := newTestPlugin(t)
+	fake.setResponseHeader("Ratelimit", `"default";r=20;t=100`)
+	fake.setResponseHeader("Ratelimit-Policy", `"default";q=1200;w=300`)
+
+	createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+
+	limiter := p.limiters.get(&TargetConfig{APIToken: testAPIToken})
+	if got := limiter.limiter.Limit(); got != 0.2 {
+		t.Errorf("expected rate to drop to 0.2 requests per second, got %v", got)
+	}
+}
+
+// =============================================================================
+// Zone Resolution Tests
+// =============================================================================
+
+func TestPlugin_ZoneNameResolvedAndCached(t *testing.T) {
+	fake := newFakeCloudflare(t, testAPIToken)
+	fake.addZone("other.com")
+	zoneID := fake.addZone(testZoneName)
+	p := fake.plugin()
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_name": "Example.com."}`, testAPIToken))
+
+	nativeID := createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+	if fake.record(zoneID, nativeID) == nil {
+		t.Fatal("expected record to be created in the named zone")
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Name != "www" {
+		t.Errorf("expected Name 'www', got '%s'", props.Name)
+	}
+
+	if n := fake.callCount("GET /zones"); n != 1 {
+		t.Errorf("expected zone name to be resolved once, got %d zone lookups", n)
+	}
+	if n := fake.callCount("GET /zones/{zone_id}"); n != 0 {
+		t.Errorf("expected no zone details lookups when zone_name is configured, got %d", n)
+	}
+}
+
+func TestPlugin_ZoneNameNotResolved(t *testing.T) {
+	fake := newFakeCloudflare(t, testAPIToken)
+	fake.addAccountZone("account-1", "shared.com")
+	fake.addAccountZone("account-2", "shared.com")
+	p := fake.plugin()
+
+	tests := map[string]string{
+		"missing":   "missing.com",
+		"ambiguous": "shared.com",
+	}
+
+	for name, zoneName := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_name": %q}`, testAPIToken, zoneName))
+
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "content": "192.0.2.1"}`),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+				t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+			}
+			if !strings.Contains(result.ProgressResult.StatusMessage, zoneName) {
+				t.Errorf("expected StatusMessage to name the zone, got '%s'", result.ProgressResult.StatusMessage)
+			}
+
+			readResult, _ := readRecord(t, p, config, "00000000000000000000000000000001")
+			if readResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+				t.Errorf("expected InvalidRequest from Read, got '%s'", readResult.ErrorCode)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Multi-Zone Target Tests
+// =============================================================================
+
+// newMultiZoneTestPlugin returns a plugin and a multi-zone target config that
+// permits example.com (by name) and example.org (by ID), but not example.net.
+func newMultiZoneTestPlugin(t *testing.T) (*Plugin, *fakeCloudflare, map[string]string, json.RawMessage) {
+	t.Helper()
+
+	fake := newFakeCloudflare(t, testAPIToken)
+	zoneIDs := map[string]string{
+		"example.com": fake.addAccountZone("acct-1", "example.com"),
+		"example.org": fake.addAccountZone("acct-1", "example.org"),
+		"example.net": fake.addAccountZone("acct-1", "example.net"),
+	}
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "account_id": "acct-1", "zones": ["example.com", %q]}`,
+		testAPIToken, zoneIDs["example.org"]))
+
+	return fake.plugin(), fake, zoneIDs, config
+}
+
+func TestPlugin_MultiZoneCRUD(t *testing.T) {
+	p, fake, zoneIDs, config := newMultiZoneTestPlugin(t)
+
+	nativeID := createRecord(t, p, config, `{"record_type": "A", "name": "www", "zone": "Example.com", "content": "192.0.2.1"}`)
+	if !strings.HasPrefix(nativeID, "example.com/") {
+		t.Errorf("expected native ID to carry the zone, got '%s'", nativeID)
+	}
+	if fake.recordCount(zoneIDs["example.com"]) != 1 {
+		t.Fatal("expected record in example.com")
+	}
+
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Zone == nil || *props.Zone != "example.com" {
+		t.Errorf("expected Zone 'example.com', got %v", props.Zone)
+	}
+	if props.Name != "www" {
+		t.Errorf("expected Name 'www', got '%s'", props.Name)
+	}
+
+	// A zone allowed by ID can also be selected by name.
+	orgID := createRecord(t, p, config, `{"record_type": "A", "name": "www", "zone": "example.org", "content": "192.0.2.2"}`)
+	_, props = readRecord(t, p, config, orgID)
+	if props.Zone == nil || *props.Zone != "example.org" {
+		t.Errorf("expected Zone 'example.org', got %v", props.Zone)
+	}
+
+	result, err := p.Delete(context.Background(), &resource.DeleteRequest{
+		NativeID:     nativeID,
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusSuccess {
+		t.Fatalf("delete failed: %s", result.ProgressResult.StatusMessage)
+	}
+	if fake.recordCount(zoneIDs["example.com"]) != 0 {
+		t.Error("expected record to be deleted from example.com")
+	}
+}
+
+func TestPlugin_MultiZoneRejectsZone(t *testing.T) {
+	p, fake, zoneIDs, config := newMultiZoneTestPlugin(t)
+
+	tests := map[string]string{
+		"not permitted": `{"record_type": "A", "name": "www", "zone": "example.net", "content": "192.0.2.1"}`,
+		"missing zone":  `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`,
+	}
+
+	for name, props := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := p.Create(context.Background(), &resource.CreateRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				Properties:   json.RawMessage(props),
+				TargetConfig: config,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+				t.Errorf("expected InvalidRequest, got '%s'", result.ProgressResult.ErrorCode)
+			}
+		})
+	}
+
+	if fake.recordCount(zoneIDs["example.net"]) != 0 {
+		t.Error("expected no record in example.net")
+	}
+}
+
+func TestPlugin_SingleZoneExplicitZone(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	otherZoneID := fake.addZone("other.com")
+
+	nativeID := createRecord(t, p, config, `{"record_type": "A", "name": "www", "zone": "example.com", "content": "192.0.2.1"}`)
+	_, props := readRecord(t, p, config, nativeID)
+	if props.Zone == nil || *props.Zone != "example.com" {
+		t.Errorf("expected Zone 'example.com', got %v", props.Zone)
+	}
+	if fake.recordCount(zoneID) != 1 {
+		t.Error("expected record in the target zone")
+	}
+
+	result, err := p.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(`{"record_type": "A", "name": "www", "zone": "other.com", "content": "192.0.2.1"}`),
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.ErrorCode != resource.OperationErrorCodeInvalidRequest {
+		t.Errorf("expected InvalidRequest for a zone outside the target, got '%s'", result.ProgressResult.ErrorCode)
+	}
+	if fake.recordCount(otherZoneID) != 0 {
+		t.Error("expected no record in other.com")
+	}
+}
+
+func TestPlugin_MultiZoneList(t *testing.T) {
+	p, fake, zoneIDs, config := newMultiZoneTestPlugin(t)
+
+	for i := 0; i < 3; i++ {
+		fake.addRecord(zoneIDs["example.com"], fakeRecord{Type: "A", Name: fmt.Sprintf("host-%d", i), Content: "192.0.2.1"})
+	}
+	fake.addRecord(zoneIDs["example.org"], fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+	fake.addRecord(zoneIDs["example.net"], fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	var nativeIDs []string
+	var pageToken *string
+	for {
+		result, err := p.List(context.Background(), &resource.ListRequest{
+			ResourceType: "CLOUDFLARE::DNS::Record",
+			TargetConfig: config,
+			PageSize:     2,
+			PageToken:    pageToken,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nativeIDs = append(nativeIDs, result.NativeIDs...)
+		if result.NextPageToken == nil {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	if len(nativeIDs) != 4 {
+		t.Fatalf("expected 4 records from permitted zones, got %d: %v", len(nativeIDs), nativeIDs)
+	}
+	for _, nativeID := range nativeIDs {
+		if _, props := readRecord(t, p, config, nativeID); props == nil {
+			t.Errorf("expected discovered record %s to be readable", nativeID)
+		}
+	}
+}
+
+// =============================================================================
+// Caching Tests
+// =============================================================================
+
+func TestPlugin_ReadCachesZoneName(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	var nativeIDs []string
+	for i := 0; i < 5; i++ {
+		nativeIDs = append(nativeIDs, fake.addRecord(zoneID, fakeRecord{Type: "A", Name: fmt.Sprintf("host-%d", i), Content: "192.0.2.1"}))
+	}
+	for _, nativeID := range nativeIDs {
+		if _, props := readRecord(t, p, config, nativeID); props == nil {
+			t.Fatalf("expected record %s to be readable", nativeID)
+		}
+	}
+
+	if n := fake.callCount("GET /zones/{zone_id}"); n != 1 {
+		t.Errorf("expected zone details to be fetched once, got %d", n)
+	}
+	if n := fake.callCount("GET /zones/{zone_id}/dns_records/{record_id}"); n != 5 {
+		t.Errorf("expected 5 record reads, got %d", n)
+	}
+}
+
+func TestPlugin_AuthErrorInvalidatesCache(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	readRecord(t, p, config, nativeID)
+
+	fake.failNext(http.StatusForbidden, cfCodeAuthentication, "Authentication error")
+	if result, _ := readRecord(t, p, config, nativeID); result.ErrorCode == "" {
+		t.Fatal("expected read to fail on authentication error")
+	}
+
+	readRecord(t, p, config, nativeID)
+	if n := fake.callCount("GET /zones/{zone_id}"); n != 2 {
+		t.Errorf("expected zone details to be re-fetched after an auth error, got %d fetches", n)
+	}
+}
+
+// =============================================================================
+// Target Config HTTP Settings Tests
+// =============================================================================
+
+func TestPlugin_BaseURLFromTargetConfig(t *testing.T) {
+	fake := newFakeCloudflare(t, testAPIToken)
+	zoneID := fake.addZone(testZoneName)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "base_url": %q}`, testAPIToken, zoneID, fake.URL()+"/"))
+
+	// No client options: the target config alone routes requests to the fake.
+	p := &Plugin{}
+	createRecord(t, p, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+
+	if fake.recordCount(zoneID) != 1 {
+		t.Errorf("expected 1 record in fake API, got %d", fake.recordCount(zoneID))
+	}
+}
+
+func TestPlugin_CABundleFromTargetConfig(t *testing.T) {
+	fake := newFakeCloudflareTLS(t, testAPIToken)
+	zoneID := fake.addZone(testZoneName)
+	props := `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`
+
+	// Without the CA bundle the self-signed certificate is rejected.
+	untrusted := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "base_url": %q}`, testAPIToken, zoneID, fake.URL()))
+	noRetries := &Plugin{clientOptions: []cloudflare.Option{cloudflare.UsingRetryPolicy(0, 0, 0)}}
+	result, err := noRetries.Create(context.Background(), &resource.CreateRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		Properties:   json.RawMessage(props),
+		TargetConfig: untrusted,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProgressResult.OperationStatus != resource.OperationStatusFailure {
+		t.Fatal("expected failure without CA bundle")
+	}
+
+	trusted := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "base_url": %q, "ca_bundle": %q}`, testAPIToken, zoneID, fake.URL(), fake.caBundle()))
+	createRecord(t, &Plugin{}, trusted, props)
+}
+
+func TestPlugin_ProxyURLFromTargetConfig(t *testing.T) {
+	fake := newFakeCloudflare(t, testAPIToken)
+	zoneID := fake.addZone(testZoneName)
+
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(proxy.Close)
+
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "base_url": %q, "proxy_url": %q, "request_timeout_seconds": 5}`, testAPIToken, zoneID, fake.URL(), proxy.URL))
+	createRecord(t, &Plugin{}, config, `{"record_type": "A", "name": "www", "content": "192.0.2.1"}`)
+
+	if proxied.Load() == 0 {
+		t.Error("expected requests to go through the proxy")
+	}
+}
+
+// =============================================================================
+// Discovery Tests
+// =============================================================================
+
+func TestPlugin_ListPaginates(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	want := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		id := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: fmt.Sprintf("host-%d", i), Content: fmt.Sprintf("192.0.2.%d", i+1)})
+		want[id] = true
+	}
+
+	got := map[string]bool{}
+	var pageToken *string
+	pages := 0
+	for {
+		result, err := p.List(context.Background(), &resource.ListRequest{
+			ResourceType: "CLOUDFLARE::DNS::Record",
+			TargetConfig: config,
+			PageSize:     2,
+			PageToken:    pageToken,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pages++
+		for _, id := range result.NativeIDs {
+			got[id] = true
+		}
+		if result.NextPageToken == nil {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	if pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d records, got %d", len(want), len(got))
+	}
+	for id := range want {
+		if !got[id] {
+			t.Errorf("expected record %s to be listed", id)
+		}
+	}
+}
+
+func TestPlugin_DiscoveredRecordsAreReadable(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: "@", Content: "v=spf1 -all", TTL: 3600})
+
+	result, err := p.List(context.Background(), &resource.ListRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.NativeIDs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(result.NativeIDs))
+	}
+
+	_, props := readRecord(t, p, config, result.NativeIDs[0])
+	if props.RecordType != "TXT" || props.Name != "@" || props.Content != "v=spf1 -all" || props.TTL != 3600 {
+		t.Errorf("unexpected properties: %+v", props)
+	}
+}
+
+func TestPlugin_ListPropagatesErrors(t *testing.T) {
+	p, _, zoneID, _ := newTestPlugin(t)
+
+	tests := map[string]struct {
+		config   string
+		expected resource.OperationErrorCode
+	}{
+		"invalid config": {`{"zone_id": "` + zoneID + `"}`, resource.OperationErrorCodeInvalidRequest},
+		"wrong token":    {`{"api_token": "wrong-token-secret", "zone_id": "` + zoneID + `"}`, resource.OperationErrorCodeInvalidCredentials},
+		"unknown zone":   {`{"api_token": "` + testAPIToken + `", "zone_name": "missing.com"}`, resource.OperationErrorCodeInvalidRequest},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := p.List(context.Background(), &resource.ListRequest{
+				ResourceType: "CLOUDFLARE::DNS::Record",
+				TargetConfig: json.RawMessage(tt.config),
+			})
+			if err == nil {
+				t.Fatalf("expected an error, got %d records", len(result.NativeIDs))
+			}
+			if !strings.HasPrefix(err.Error(), string(tt.expected)+": ") {
+				t.Errorf("expected error to start with %s, got %q", tt.expected, err)
+			}
+			if strings.Contains(err.Error(), "wrong-token-secret") {
+				t.Errorf("expected the API token to be redacted, got %q", err)
+			}
+		})
+	}
+}
+
+func TestPlugin_ListThrottled(t *testing.T) {
+	p, fake, zoneID, _ := newTestPlugin(t)
+	config := json.RawMessage(fmt.Sprintf(`{"api_token": %q, "zone_id": %q, "retry_max_attempts": 1}`, testAPIToken, zoneID))
+	fake.failNext(http.StatusTooManyRequests, 971, "Please wait and consider throttling your request speed")
+
+	_, err := p.List(context.Background(), &resource.ListRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err == nil || !strings.Contains(err.Error(), string(resource.OperationErrorCodeThrottling)) {
+		t.Errorf("expected a Throttling error so formae retries, got %v", err)
+	}
+}
+
+// capturingLogger records log messages for assertions.
+type capturingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *capturingLogger) log(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *capturingLogger) Debug(msg string, attrs ...any) { l.log(msg) }
+func (l *capturingLogger) Info(msg string, attrs ...any)  { l.log(msg) }
+func (l *capturingLogger) Warn(msg string, attrs ...any)  { l.log(msg) }
+func (l *capturingLogger) Error(msg string, attrs ...any) { l.log(msg) }
+func (l *capturingLogger) With(attrs ...any) plugin.Logger {
+	return l
+}
+
+func TestPlugin_ReadFailureLogsMessage(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	nativeID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	logger := &capturingLogger{}
+	ctx := plugin.WithLogger(context.Background(), logger)
+
+	fake.failNext(http.StatusForbidden, cfCodeAuthentication, "Authentication error: token "+testAPIToken+" lacks permission")
+	result, err := p.Read(ctx, &resource.ReadRequest{
+		NativeID:     nativeID,
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ErrorCode != resource.OperationErrorCodeAccessDenied {
+		t.Errorf("expected AccessDenied, got '%s'", result.ErrorCode)
+	}
+
+	if len(logger.messages) != 1 {
+		t.Fatalf("expected 1 log message, got %d", len(logger.messages))
+	}
+	msg := logger.messages[0]
+	if !strings.Contains(msg, "Authentication error") {
+		t.Errorf("expected the Cloudflare error in the message, got %q", msg)
+	}
+	if strings.Contains(msg, testAPIToken) {
+		t.Errorf("expected the API token to be redacted, got %q", msg)
+	}
+}
+
+func TestPlugin_ListSkipsUnmanageableRecords(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	aID := fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+	txtID := fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: "@", Content: "v=spf1 -all"})
+	fake.addRecord(zoneID, fakeRecord{Type: "OPENPGPKEY", Name: "key", Content: "mQINBFit2jsBEADrbl5vjVxYeAE0g0IDYCBpHirv1Sjlqxx5gjtPhb2YhvyDMXjq"})
+	fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "app", Content: "192.0.2.2", Meta: map[string]any{"read_only": true}})
+	fake.addRecord(zoneID, fakeRecord{Type: "HTTPS", Name: "svc", Content: `1 . alpn="h2" unknown="x"`})
+	httpsID := fake.addRecord(zoneID, fakeRecord{Type: "HTTPS", Name: "@", Content: `1 . mandatory="alpn" alpn="h2" no-default-alpn`})
+
+	result, err := p.List(context.Background(), &resource.ListRequest{
+		ResourceType: "CLOUDFLARE::DNS::Record",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{aID, txtID, httpsID}
+	if !slices.Equal(result.NativeIDs, expected) {
+		t.Errorf("expected only %v, got %v", expected, result.NativeIDs)
+	}
+}
+
+func TestPlugin_ListByRecordType(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+
+	fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+	txtID := fake.addRecord(zoneID, fakeRecord{Type: "TXT", Name: "@", Content: "v=spf1 -all"})
+
+	result, err := p.List(context.Background(), &resource.ListRequest{
+		ResourceType:         "CLOUDFLARE::DNS::Record",
+		TargetConfig:         config,
+		AdditionalProperties: map[string]string{"record_type": "txt"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.NativeIDs) != 1 || result.NativeIDs[0] != txtID {
+		t.Errorf("expected only [%s], got %v", txtID, result.NativeIDs)
+	}
+	if got := fake.lastQuery("GET /zones/{zone_id}/dns_records").Get("type"); got != "TXT" {
+		t.Errorf("expected the type to be filtered by the API, got %q", got)
+	}
+
+	_, err = p.List(context.Background(), &resource.ListRequest{
+		ResourceType:         "CLOUDFLARE::DNS::Record",
+		TargetConfig:         config,
+		AdditionalProperties: map[string]string{"record_type": "OPENPGPKEY"},
+	})
+	if err == nil {
+		t.Error("expected an error for an unsupported record type")
+	}
+}
+
+func TestPlugin_ListOtherResourceType(t *testing.T) {
+	p, fake, zoneID, config := newTestPlugin(t)
+	fake.addRecord(zoneID, fakeRecord{Type: "A", Name: "www", Content: "192.0.2.1"})
+
+	result, err := p.List(context.Background(), &resource.ListRequest{
+		ResourceType: "CLOUDFLARE::DNS::Zone",
+		TargetConfig: config,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.NativeIDs) != 0 {
+		t.Errorf("expected no records for another resource type, got %v", result.NativeIDs)
+	}
+	if n := fake.callCount("GET /zones/{zone_id}/dns_records"); n != 0 {
+		t.Errorf("expected no API calls, got %d", n)
+	}
+}
@@ -0,0 +1,306 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRequestsPerSecond is the per-token request rate used unless the
+	// target config overrides it. Cloudflare's default API limit is 1200
+	// requests per 5 minutes.
+	defaultRequestsPerSecond = 4.0
+
+	// namespaceTokenBudget is the number of API tokens the namespace ceiling
+	// leaves room for at the default per-token rate.
+	namespaceTokenBudget = 12
+
+	// namespaceRequestsPerSecond is the ceiling formae applies to plugin
+	// operations across all Cloudflare targets. Cloudflare limits each API
+	// token, not the plugin, and an operation may make several API requests,
+	// so the per-token buckets below are what keeps each token within its
+	// limit. The ceiling is derived from the per-token budget so it follows
+	// the default rate: it lets a dozen targets with their own tokens run at
+	// full speed instead of throttling all tokens together to the rate of
+	// one, while bounding the operations formae hands the plugin at once.
+	// Operations beyond it wait in formae rather than in the per-token
+	// buckets, where they would run into their deadlines.
+	namespaceRequestsPerSecond = namespaceTokenBudget * defaultRequestsPerSecond
+
+	// rateLimitSlowdownThreshold is the fraction of the quota below which
+	// requests are spread over the time left until the quota resets.
+	rateLimitSlowdownThreshold = 0.2
+
+	// minRequestsPerSecond keeps a slowed-down limiter from stalling entirely.
+	minRequestsPerSecond = 0.05
+)
+
+// tokenLimiter is the token bucket shared by all requests made with one API
+// token. It starts at a static ceiling and slows down as Cloudflare reports
+// the remaining quota dropping, e.g. because other tools share the token.
+type tokenLimiter struct {
+	limiter *rate.Limiter
+
+	mu          sync.Mutex
+	ceiling     rate.Limit
+	pausedUntil time.Time
+}
+
+func newTokenLimiter(rps float64) *tokenLimiter {
+	return &tokenLimiter{
+		limiter: rate.NewLimiter(rate.Limit(rps), burstFor(rps)),
+		ceiling: rate.Limit(rps),
+	}
+}
+
+// setCeiling changes the static ceiling, e.g. after the target config changed.
+func (l *tokenLimiter) setCeiling(rps float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ceiling == rate.Limit(rps) {
+		return
+	}
+	// Follow the new ceiling unless the limiter is currently slowed down.
+	if current := l.limiter.Limit(); current == l.ceiling || current > rate.Limit(rps) {
+		l.limiter.SetLimit(rate.Limit(rps))
+	}
+	l.ceiling = rate.Limit(rps)
+	l.limiter.SetBurst(burstFor(rps))
+}
+
+// throttledError is returned when a request cannot get through the token's
+// limiter before its context deadline. The plugin held the request back, so
+// it is reported as throttling rather than as a network failure, and it is
+// not retried.
+type throttledError struct {
+	err error
+}
+
+func (e *throttledError) Error() string {
+	return "request throttled to stay within the API token's rate limit: " + e.err.Error()
+}
+
+func (e *throttledError) Unwrap() error {
+	return e.err
+}
+
+// wait blocks until a request may be sent. It returns a throttledError if
+// that would be after the context deadline.
+func (l *tokenLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	pause := time.Until(l.pausedUntil)
+	l.mu.Unlock()
+
+	if pause > 0 {
+		if !fitsDeadline(ctx, pause) {
+			return &throttledError{err: fmt.Errorf("paused for %s until the quota resets", pause.Round(time.Second))}
+		}
+		timer := time.NewTimer(pause)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	if err := l.limiter.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		// The limiter refuses waits that would exceed the deadline.
+		return &throttledError{err: err}
+	}
+	return nil
+}
+
+// observe adjusts the rate from the rate-limit headers of a response.
+func (l *tokenLimiter) observe(resp *http.Response, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			l.pausedUntil = now.Add(retryAfter)
+		}
+	}
+
+	quota, ok := parseRateLimitHeaders(resp.Header, now)
+	if !ok {
+		return
+	}
+
+	if quota.remaining <= 0 && quota.reset > 0 {
+		l.pausedUntil = now.Add(quota.reset)
+	}
+
+	limit := l.ceiling
+	if quota.reset > 0 && (quota.limit == 0 || float64(quota.remaining) < rateLimitSlowdownThreshold*float64(quota.limit)) {
+		sustainable := rate.Limit(float64(quota.remaining) / quota.reset.Seconds())
+		limit = min(limit, max(sustainable, minRequestsPerSecond))
+	}
+	l.limiter.SetLimitAt(now, limit)
+}
+
+// burstFor allows short bursts of up to one second's worth of requests.
+func burstFor(rps float64) int {
+	return max(1, int(math.Floor(rps)))
+}
+
+// rateLimitQuota is the quota Cloudflare reports in its response headers.
+type rateLimitQuota struct {
+	limit     int           // requests per window; 0 if unknown
+	remaining int           // requests left in the current window
+	reset     time.Duration // time until the window resets
+}
+
+// parseRateLimitHeaders reads the quota from Cloudflare's rate-limit headers.
+// It understands the structured "Ratelimit" / "Ratelimit-Policy" headers
+// (e.g. `"default";r=50;t=30` and `"default";q=1200;w=300`) as well as the
+// legacy X-RateLimit-* headers.
+func parseRateLimitHeaders(h http.Header, now time.Time) (rateLimitQuota, bool) {
+	if value := h.Get("Ratelimit"); value != "" {
+		quota, ok := parseStructuredRateLimit(value)
+		if ok {
+			if policy := h.Get("Ratelimit-Policy"); policy != "" {
+				quota.limit = parseStructuredRateLimitPolicy(policy)
+			}
+			return quota, true
+		}
+	}
+
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return rateLimitQuota{}, false
+	}
+	quota := rateLimitQuota{remaining: remaining}
+	quota.limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		// Either seconds until reset or a Unix timestamp.
+		if reset > 1_000_000_000 {
+			quota.reset = max(time.Unix(reset, 0).Sub(now), 0)
+		} else {
+			quota.reset = time.Duration(reset) * time.Second
+		}
+	}
+	return quota, true
+}
+
+// parseStructuredRateLimit parses a "Ratelimit" header. If it lists several
+// policies, the one with the fewest remaining requests wins.
+func parseStructuredRateLimit(value string) (rateLimitQuota, bool) {
+	var quota rateLimitQuota
+	found := false
+
+	for _, item := range strings.Split(value, ",") {
+		params := structuredParams(item)
+		remaining, err := strconv.Atoi(params["r"])
+		if err != nil {
+			continue
+		}
+		reset, _ := strconv.Atoi(params["t"])
+		if !found || remaining < quota.remaining {
+			quota = rateLimitQuota{remaining: remaining, reset: time.Duration(reset) * time.Second}
+			found = true
+		}
+	}
+	return quota, found
+}
+
+// parseStructuredRateLimitPolicy returns the smallest quota listed in a
+// "Ratelimit-Policy" header, or 0 if none is listed.
+func parseStructuredRateLimitPolicy(value string) int {
+	limit := 0
+	for _, item := range strings.Split(value, ",") {
+		if q, err := strconv.Atoi(structuredParams(item)["q"]); err == nil && (limit == 0 || q < limit) {
+			limit = q
+		}
+	}
+	return limit
+}
+
+// structuredParams returns the parameters of a structured header item,
+// e.g. {"r": "50", "t": "30"} for `"default";r=50;t=30`.
+func structuredParams(item string) map[string]string {
+	params := make(map[string]string)
+	for _, param := range strings.Split(item, ";")[1:] {
+		if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok {
+			params[key] = value
+		}
+	}
+	return params
+}
+
+// =============================================================================
+// Per-Token Limiters
+// =============================================================================
+
+// rateLimiters holds one tokenLimiter per API token, so every target and
+// client using the same token shares its quota.
+type rateLimiters struct {
+	// defaultRPS overrides defaultRequestsPerSecond. Unit tests use this to
+	// avoid being throttled by the plugin.
+	defaultRPS float64
+
+	mu       sync.Mutex
+	limiters map[string]*tokenLimiter
+}
+
+// get returns the limiter for the config's API token.
+func (r *rateLimiters) get(config *TargetConfig) *tokenLimiter {
+	rps := config.MaxRequestsPerSecond
+	if rps <= 0 {
+		rps = r.defaultRPS
+	}
+	if rps <= 0 {
+		rps = defaultRequestsPerSecond
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := hashKey(config.APIToken)
+	limiter, ok := r.limiters[key]
+	if !ok {
+		if r.limiters == nil {
+			r.limiters = make(map[string]*tokenLimiter)
+		}
+		limiter = newTokenLimiter(rps)
+		r.limiters[key] = limiter
+		return limiter
+	}
+	limiter.setCeiling(rps)
+	return limiter
+}
+
+// rateLimitTransport makes every request wait for the token's limiter and
+// feeds the rate-limit headers of every response back into it.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *tokenLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.limiter.observe(resp, time.Now())
+	return resp, nil
+}
@@ -0,0 +1,181 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected rateLimitQuota
+		ok       bool
+	}{
+		{
+			name:     "none",
+			header:   http.Header{},
+			expected: rateLimitQuota{},
+			ok:       false,
+		},
+		{
+			name: "structured",
+			header: http.Header{
+				"Ratelimit":        []string{`"default";r=50;t=30`},
+				"Ratelimit-Policy": []string{`"default";q=1200;w=300`},
+			},
+			expected: rateLimitQuota{limit: 1200, remaining: 50, reset: 30 * time.Second},
+			ok:       true,
+		},
+		{
+			name: "structured with several policies",
+			header: http.Header{
+				"Ratelimit":        []string{`"burst";r=90;t=10, "default";r=40;t=200`},
+				"Ratelimit-Policy": []string{`"burst";q=100;w=10, "default";q=1200;w=300`},
+			},
+			expected: rateLimitQuota{limit: 100, remaining: 40, reset: 200 * time.Second},
+			ok:       true,
+		},
+		{
+			name: "legacy",
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"1200"},
+				"X-Ratelimit-Remaining": []string{"10"},
+				"X-Ratelimit-Reset":     []string{"60"},
+			},
+			expected: rateLimitQuota{limit: 1200, remaining: 10, reset: time.Minute},
+			ok:       true,
+		},
+		{
+			name: "legacy with timestamp",
+			header: http.Header{
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{strconv.FormatInt(now.Add(90*time.Second).Unix(), 10)},
+			},
+			expected: rateLimitQuota{remaining: 0, reset: 90 * time.Second},
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota, ok := parseRateLimitHeaders(tt.header, now)
+			if ok != tt.ok || quota != tt.expected {
+				t.Errorf("expected %+v (%v), got %+v (%v)", tt.expected, tt.ok, quota, ok)
+			}
+		})
+	}
+}
+
+func rateLimitResponse(remaining, limit, reset int) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Ratelimit":        []string{`"default";r=` + strconv.Itoa(remaining) + `;t=` + strconv.Itoa(reset)},
+			"Ratelimit-Policy": []string{`"default";q=` + strconv.Itoa(limit) + `;w=300`},
+		},
+	}
+}
+
+func TestTokenLimiter_SlowsDownAsQuotaDrops(t *testing.T) {
+	l := newTokenLimiter(10)
+	now := time.Now()
+
+	// Plenty of quota left: stay at the ceiling.
+	l.observe(rateLimitResponse(1000, 1200, 100), now)
+	if got := l.limiter.Limit(); got != 10 {
+		t.Errorf("expected ceiling 10, got %v", got)
+	}
+
+	// Below the threshold: spread the remaining quota until the reset.
+	l.observe(rateLimitResponse(100, 1200, 200), now)
+	if got := l.limiter.Limit(); got != 0.5 {
+		t.Errorf("expected 0.5 requests per second, got %v", got)
+	}
+
+	// Quota replenished: back to the ceiling.
+	l.observe(rateLimitResponse(1200, 1200, 300), now)
+	if got := l.limiter.Limit(); got != 10 {
+		t.Errorf("expected ceiling 10 after reset, got %v", got)
+	}
+}
+
+func TestTokenLimiter_PausesWhenExhausted(t *testing.T) {
+	l := newTokenLimiter(10)
+	now := time.Now()
+
+	l.observe(rateLimitResponse(0, 1200, 30), now)
+	if !l.pausedUntil.Equal(now.Add(30 * time.Second)) {
+		t.Errorf("expected pause until reset, got %s", l.pausedUntil.Sub(now))
+	}
+	if got := l.limiter.Limit(); got != minRequestsPerSecond {
+		t.Errorf("expected minimum rate, got %v", got)
+	}
+
+	l.observe(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"45"}},
+	}, now)
+	if !l.pausedUntil.Equal(now.Add(45 * time.Second)) {
+		t.Errorf("expected pause for Retry-After, got %s", l.pausedUntil.Sub(now))
+	}
+}
+
+func TestTokenLimiter_ThrottlesPastDeadline(t *testing.T) {
+	l := newTokenLimiter(10)
+	l.observe(rateLimitResponse(0, 1200, 30), time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := l.wait(ctx)
+	var throttledErr *throttledError
+	if !errors.As(err, &throttledErr) {
+		t.Fatalf("expected throttledError, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("expected wait to fail without waiting for the deadline")
+	}
+}
+
+func TestRateLimiters_SharedPerToken(t *testing.T) {
+	var limiters rateLimiters
+
+	a := limiters.get(&TargetConfig{APIToken: "token-a", ZoneID: "zone-1"})
+	b := limiters.get(&TargetConfig{APIToken: "token-a", ZoneID: "zone-2"})
+	c := limiters.get(&TargetConfig{APIToken: "token-b", ZoneID: "zone-1"})
+
+	if a != b {
+		t.Error("expected targets sharing a token to share a limiter")
+	}
+	if a == c {
+		t.Error("expected different tokens to have different limiters")
+	}
+	if got := a.limiter.Limit(); got != defaultRequestsPerSecond {
+		t.Errorf("expected default rate %v, got %v", defaultRequestsPerSecond, got)
+	}
+
+	limiters.get(&TargetConfig{APIToken: "token-a", MaxRequestsPerSecond: 2})
+	if got := a.limiter.Limit(); got != rate.Limit(2) {
+		t.Errorf("expected overridden ceiling 2, got %v", got)
+	}
+
+	limiters.get(&TargetConfig{APIToken: "token-a", MaxRequestsPerSecond: 25})
+	if got := a.limiter.Limit(); got != rate.Limit(25) {
+		t.Errorf("expected raised ceiling 25, got %v", got)
+	}
+}
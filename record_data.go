@@ -0,0 +1,1413 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// =============================================================================
+// Structured Record Data
+// =============================================================================
+
+// Record types whose content consists of several fields can be given either
+// as content string or as a structured data block. The plugin derives the
+// other form, so both always agree: content is sent in Cloudflare's canonical
+// format and Read reports both forms parsed from the record's content.
+
+// recordData is the structured form of a record's content.
+type recordData interface {
+	// parseContent parses record content in presentation format.
+	parseContent(content string) error
+
+	// validate checks the fields and brings them into canonical form.
+	validate() error
+
+	// content formats the fields as canonical record content.
+	content() string
+
+	// cloudflareData returns the data object sent to Cloudflare.
+	cloudflareData() any
+}
+
+// recordFields is implemented by data that also covers record properties
+// outside content, such as the priority and service name of SRV records.
+type recordFields interface {
+	// syncFields fills in fields missing on either side and reports fields
+	// on which the data and the other properties disagree.
+	syncFields(props *DNSRecordProperties) error
+}
+
+// recordDefaults is implemented by data whose omitted fields default to
+// values other than zero.
+type recordDefaults interface {
+	// setDefaults resets the data to its defaults.
+	setDefaults()
+}
+
+// recordDataTypes maps record types with a structured data form to a
+// constructor of their data.
+var recordDataTypes = map[string]func() recordData{
+	"HTTPS":  func() recordData { return &svcbData{} },
+	"SVCB":   func() recordData { return &svcbData{} },
+	"SRV":    func() recordData { return &srvData{} },
+	"CAA":    func() recordData { return &caaData{} },
+	"TLSA":   func() recordData { return &tlsaData{} },
+	"SMIMEA": func() recordData { return &tlsaData{} },
+	"SSHFP":  func() recordData { return &sshfpData{} },
+	"CERT":   func() recordData { return &certData{} },
+	"DS":     func() recordData { return &dsData{} },
+	"DNSKEY": func() recordData { return &dnskeyData{} },
+	"LOC":    func() recordData { return &locData{} },
+	"NAPTR":  func() recordData { return &naptrData{} },
+	"URI":    func() recordData { return &uriData{} },
+}
+
+// normalizeRecordData validates the content and data of a record and fills in
+// whichever of the two is missing, both in canonical form.
+func normalizeRecordData(props *DNSRecordProperties) error {
+	newData, structured := recordDataTypes[props.RecordType]
+	if !structured {
+		if len(props.Data) > 0 {
+			return fmt.Errorf("data is not supported for %s records", props.RecordType)
+		}
+		return nil
+	}
+
+	data := newData()
+	if len(props.Data) > 0 {
+		if err := decodeRecordData(props.Data, data); err != nil {
+			return err
+		}
+	} else if err := data.parseContent(props.Content); err != nil {
+		return fmt.Errorf("invalid %s content %q: %w", props.RecordType, props.Content, err)
+	}
+	if fields, ok := data.(recordFields); ok {
+		if err := fields.syncFields(props); err != nil {
+			return err
+		}
+	}
+	if err := data.validate(); err != nil {
+		return fmt.Errorf("invalid %s data: %w", props.RecordType, err)
+	}
+
+	// Content given alongside data must describe the same record.
+	if len(props.Data) > 0 && props.Content != "" {
+		given := newData()
+		err := given.parseContent(props.Content)
+		if err == nil {
+			err = given.validate()
+		}
+		if err != nil || given.content() != data.content() {
+			return fmt.Errorf("content %q does not match data (%q)", props.Content, data.content())
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s data: %w", props.RecordType, err)
+	}
+	props.Content = data.content()
+	props.Data = encoded
+
+	return nil
+}
+
+// cloudflareRecordData returns the data object to send to Cloudflare for
+// normalized properties, or nil if the record type has no structured form.
+func cloudflareRecordData(props *DNSRecordProperties) any {
+	newData, structured := recordDataTypes[props.RecordType]
+	if !structured || len(props.Data) == 0 {
+		return nil
+	}
+	data := newData()
+	if err := decodeRecordData(props.Data, data); err != nil {
+		return nil
+	}
+	return data.cloudflareData()
+}
+
+// recordDataFromProperties parses the content of a record read from
+// Cloudflare into its structured form. It returns nil if the type has no
+// structured form or the record cannot be represented by it.
+func recordDataFromProperties(props *DNSRecordProperties) recordData {
+	newData, structured := recordDataTypes[props.RecordType]
+	if !structured {
+		return nil
+	}
+	data := newData()
+	if data.parseContent(props.Content) != nil {
+		return nil
+	}
+	if fields, ok := data.(recordFields); ok && fields.syncFields(props) != nil {
+		return nil
+	}
+	if data.validate() != nil {
+		return nil
+	}
+	return data
+}
+
+// decodeRecordData decodes a data block, rejecting unknown fields so typos
+// are reported instead of silently dropped.
+func decodeRecordData(raw json.RawMessage, data recordData) error {
+	if defaults, ok := data.(recordDefaults); ok {
+		defaults.setDefaults()
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// HTTPS and SVCB
+// =============================================================================
+
+// svcbData is the data of HTTPS and SVCB records (RFC 9460).
+type svcbData struct {
+	Priority      int               `json:"priority"`                  // 0 for AliasMode
+	Target        string            `json:"target"`                    // "." for the owner name
+	Mandatory     []string          `json:"mandatory,omitempty"`       // keys clients must support
+	ALPN          []string          `json:"alpn,omitempty"`            // e.g. ["h3", "h2"]
+	NoDefaultALPN bool              `json:"no_default_alpn,omitempty"` // no default protocol
+	Port          *int              `json:"port,omitempty"`            // alternative port
+	IPv4Hint      []string          `json:"ipv4hint,omitempty"`        // IPv4 addresses
+	ECH           string            `json:"ech,omitempty"`             // base64 ECHConfigList
+	IPv6Hint      []string          `json:"ipv6hint,omitempty"`        // IPv6 addresses
+	DoHPath       string            `json:"dohpath,omitempty"`         // DoH URI template (RFC 9461)
+	OHTTP         bool              `json:"ohttp,omitempty"`           // Oblivious HTTP (RFC 9540)
+	Params        map[string]string `json:"params,omitempty"`          // other keys, e.g. {"key65001": "x"}
+}
+
+// svcParamKeys are the registered SvcParam keys, indexed by their number.
+var svcParamKeys = []string{"mandatory", "alpn", "no-default-alpn", "port", "ipv4hint", "ech", "ipv6hint", "dohpath", "ohttp"}
+
+// svcParamKeyPattern matches SvcParam keys in their generic form.
+var svcParamKeyPattern = regexp.MustCompile(`^key(0|[1-9][0-9]{0,4})$`)
+
+// svcParamKey returns the number and presentation name of a SvcParam key:
+// the registered name, or "keyNNNN" for other keys.
+func svcParamKey(key string) (int, string, error) {
+	key = strings.ToLower(key)
+	if i := slices.Index(svcParamKeys, key); i >= 0 {
+		return i, key, nil
+	}
+	match := svcParamKeyPattern.FindStringSubmatch(key)
+	if match == nil {
+		return 0, "", fmt.Errorf("unsupported SvcParam %q", key)
+	}
+	number, _ := strconv.Atoi(match[1])
+	switch {
+	case number == 65535:
+		return 0, "", fmt.Errorf("SvcParam key65535 is reserved")
+	case number > 65535:
+		return 0, "", fmt.Errorf("unsupported SvcParam %q", key)
+	case number < len(svcParamKeys):
+		return number, svcParamKeys[number], nil
+	}
+	return number, key, nil
+}
+
+func (d *svcbData) parseContent(content string) error {
+	fields := strings.Fields(content)
+	if len(fields) < 2 {
+		return fmt.Errorf("expected \"priority target [params]\"")
+	}
+
+	priority, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("invalid priority %q", fields[0])
+	}
+	*d = svcbData{Priority: priority, Target: fields[1]}
+
+	params, err := splitQuoted(strings.Join(fields[2:], " "))
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		value = strings.Trim(value, `"`)
+		_, name, err := svcParamKey(key)
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "mandatory":
+			d.Mandatory = strings.Split(value, ",")
+		case "alpn":
+			d.ALPN = strings.Split(value, ",")
+		case "no-default-alpn":
+			if value != "" {
+				return fmt.Errorf("no-default-alpn takes no value")
+			}
+			d.NoDefaultALPN = true
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid port %q", value)
+			}
+			d.Port = &port
+		case "ipv4hint":
+			d.IPv4Hint = strings.Split(value, ",")
+		case "ech":
+			d.ECH = value
+		case "ipv6hint":
+			d.IPv6Hint = strings.Split(value, ",")
+		case "dohpath":
+			d.DoHPath = value
+		case "ohttp":
+			if value != "" {
+				return fmt.Errorf("ohttp takes no value")
+			}
+			d.OHTTP = true
+		default:
+			if d.Params == nil {
+				d.Params = make(map[string]string)
+			}
+			d.Params[name] = value
+		}
+	}
+	return nil
+}
+
+// splitQuoted splits s on spaces outside quotes. Quotes are kept.
+func splitQuoted(s string) ([]string, error) {
+	var params []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				params = append(params, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if current.Len() > 0 {
+		params = append(params, current.String())
+	}
+	return params, nil
+}
+
+func (d *svcbData) validate() error {
+	if d.Priority < 0 || d.Priority > 65535 {
+		return fmt.Errorf("priority must be between 0 and 65535, got %d", d.Priority)
+	}
+	d.Target = strings.ToLower(d.Target)
+	if d.Target != "." {
+		d.Target = strings.TrimSuffix(d.Target, ".")
+	}
+	if d.Target == "" || strings.ContainsAny(d.Target, " \t\"") {
+		return fmt.Errorf("invalid target %q", d.Target)
+	}
+	if d.Priority == 0 && d.hasParams() {
+		return fmt.Errorf("records with priority 0 (AliasMode) must not have SvcParams")
+	}
+
+	for _, protocol := range d.ALPN {
+		if protocol == "" || strings.ContainsAny(protocol, ", \t\"") {
+			return fmt.Errorf("invalid alpn protocol %q", protocol)
+		}
+	}
+	if d.NoDefaultALPN && len(d.ALPN) == 0 {
+		return fmt.Errorf("no_default_alpn requires alpn")
+	}
+	if d.Port != nil && (*d.Port < 1 || *d.Port > 65535) {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", *d.Port)
+	}
+	for i, addr := range d.IPv4Hint {
+		ip := net.ParseIP(addr)
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("invalid ipv4hint address %q", addr)
+		}
+		d.IPv4Hint[i] = ip.String()
+	}
+	for i, addr := range d.IPv6Hint {
+		ip := net.ParseIP(addr)
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("invalid ipv6hint address %q", addr)
+		}
+		d.IPv6Hint[i] = ip.String()
+	}
+	if d.ECH != "" {
+		if _, err := base64.StdEncoding.DecodeString(d.ECH); err != nil {
+			return fmt.Errorf("ech must be base64: %w", err)
+		}
+	}
+	if d.DoHPath != "" && (!strings.HasPrefix(d.DoHPath, "/") || !strings.Contains(d.DoHPath, "{?dns}") || strings.ContainsAny(d.DoHPath, " \t\"")) {
+		return fmt.Errorf("dohpath must be a relative URI template with a {?dns} variable, got %q", d.DoHPath)
+	}
+
+	params := make(map[string]string, len(d.Params))
+	for key, value := range d.Params {
+		number, name, err := svcParamKey(key)
+		if err != nil {
+			return err
+		}
+		if number < len(svcParamKeys) {
+			return fmt.Errorf("set %s instead of %s in params", strings.ReplaceAll(name, "-", "_"), key)
+		}
+		if strings.ContainsAny(value, " \t\"") {
+			return fmt.Errorf("invalid value %q for SvcParam %s", value, name)
+		}
+		params[name] = value
+	}
+	d.Params = params
+	if len(d.Params) == 0 {
+		d.Params = nil
+	}
+
+	return d.validateMandatory()
+}
+
+// validateMandatory checks that the mandatory keys are present and stores
+// them by name in key order.
+func (d *svcbData) validateMandatory() error {
+	numbers := make([]int, 0, len(d.Mandatory))
+	for _, key := range d.Mandatory {
+		number, name, err := svcParamKey(key)
+		if err != nil {
+			return fmt.Errorf("invalid mandatory key: %w", err)
+		}
+		if name == "mandatory" {
+			return fmt.Errorf("mandatory must not list itself")
+		}
+		if slices.Contains(numbers, number) {
+			return fmt.Errorf("mandatory lists %s more than once", name)
+		}
+		if !d.hasParam(number, name) {
+			return fmt.Errorf("mandatory key %s is not set", name)
+		}
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+	for i, number := range numbers {
+		d.Mandatory[i] = svcParamName(number)
+	}
+	return nil
+}
+
+// hasParam reports whether the SvcParam with the given number and name is set.
+func (d *svcbData) hasParam(number int, name string) bool {
+	switch number {
+	case 1:
+		return len(d.ALPN) > 0
+	case 2:
+		return d.NoDefaultALPN
+	case 3:
+		return d.Port != nil
+	case 4:
+		return len(d.IPv4Hint) > 0
+	case 5:
+		return d.ECH != ""
+	case 6:
+		return len(d.IPv6Hint) > 0
+	case 7:
+		return d.DoHPath != ""
+	case 8:
+		return d.OHTTP
+	}
+	_, ok := d.Params[name]
+	return ok
+}
+
+// svcParamName returns the presentation name of a SvcParam key number.
+func svcParamName(number int) string {
+	if number < len(svcParamKeys) {
+		return svcParamKeys[number]
+	}
+	return "key" + strconv.Itoa(number)
+}
+
+func (d *svcbData) hasParams() bool {
+	return len(d.Mandatory) > 0 || len(d.ALPN) > 0 || d.NoDefaultALPN || d.Port != nil ||
+		len(d.IPv4Hint) > 0 || d.ECH != "" || len(d.IPv6Hint) > 0 || d.DoHPath != "" || d.OHTTP || len(d.Params) > 0
+}
+
+// content formats the record as Cloudflare does, with SvcParams in key order.
+func (d *svcbData) content() string {
+	content := strconv.Itoa(d.Priority) + " " + d.Target
+	if params := d.params(); params != "" {
+		content += " " + params
+	}
+	return content
+}
+
+// params formats the SvcParams in the order of their keys (RFC 9460).
+func (d *svcbData) params() string {
+	var params []string
+	if len(d.Mandatory) > 0 {
+		params = append(params, fmt.Sprintf("mandatory=%q", strings.Join(d.Mandatory, ",")))
+	}
+	if len(d.ALPN) > 0 {
+		params = append(params, fmt.Sprintf("alpn=%q", strings.Join(d.ALPN, ",")))
+	}
+	if d.NoDefaultALPN {
+		params = append(params, "no-default-alpn")
+	}
+	if d.Port != nil {
+		params = append(params, fmt.Sprintf("port=%q", strconv.Itoa(*d.Port)))
+	}
+	if len(d.IPv4Hint) > 0 {
+		params = append(params, fmt.Sprintf("ipv4hint=%q", strings.Join(d.IPv4Hint, ",")))
+	}
+	if d.ECH != "" {
+		params = append(params, fmt.Sprintf("ech=%q", d.ECH))
+	}
+	if len(d.IPv6Hint) > 0 {
+		params = append(params, fmt.Sprintf("ipv6hint=%q", strings.Join(d.IPv6Hint, ",")))
+	}
+	if d.DoHPath != "" {
+		params = append(params, fmt.Sprintf("dohpath=%q", d.DoHPath))
+	}
+	if d.OHTTP {
+		params = append(params, "ohttp")
+	}
+
+	numbers := make([]int, 0, len(d.Params))
+	for key := range d.Params {
+		number, _, _ := svcParamKey(key)
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+	for _, number := range numbers {
+		key := svcParamName(number)
+		if value := d.Params[key]; value != "" {
+			params = append(params, fmt.Sprintf("%s=%q", key, value))
+		} else {
+			params = append(params, key)
+		}
+	}
+	return strings.Join(params, " ")
+}
+
+func (d *svcbData) cloudflareData() any {
+	return map[string]any{
+		"priority": d.Priority,
+		"target":   d.Target,
+		"value":    d.params(),
+	}
+}
+
+// =============================================================================
+// SRV
+// =============================================================================
+
+// srvLabelPattern matches the service and protocol labels of SRV names.
+var srvLabelPattern = regexp.MustCompile(`^_[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// srvData is the data of SRV records (RFC 2782). Service, protocol and name
+// are the parts of the record name and the priority is the record's priority;
+// they are filled in from the record if omitted.
+type srvData struct {
+	Service  string `json:"service"`  // e.g. "_sip"
+	Proto    string `json:"proto"`    // e.g. "_tcp"
+	Name     string `json:"name"`     // name below the service labels; "@" for the apex
+	Priority *int   `json:"priority"` // lower is preferred
+	Weight   int    `json:"weight"`   // relative weight among equal priorities
+	Port     int    `json:"port"`     // service port
+	Target   string `json:"target"`   // "." if the service is unavailable
+}
+
+// parseContent parses SRV content, which Cloudflare stores without the
+// priority: "weight port target".
+func (d *srvData) parseContent(content string) error {
+	fields := strings.Fields(content)
+	if len(fields) != 3 {
+		return fmt.Errorf("expected \"weight port target\"")
+	}
+
+	weight, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("invalid weight %q", fields[0])
+	}
+	port, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("invalid port %q", fields[1])
+	}
+	*d = srvData{Weight: weight, Port: port, Target: fields[2]}
+	return nil
+}
+
+func (d *srvData) syncFields(props *DNSRecordProperties) error {
+	service, proto, name, ok := splitSRVName(props.Name)
+	if !ok {
+		return fmt.Errorf("SRV record name must start with _service._proto, got %q", props.Name)
+	}
+	for _, field := range []struct {
+		key      string
+		data     *string
+		fromName string
+	}{
+		{"service", &d.Service, service},
+		{"proto", &d.Proto, proto},
+	} {
+		if *field.data == "" {
+			*field.data = field.fromName
+		} else if !strings.EqualFold(strings.TrimSuffix(*field.data, "."), field.fromName) {
+			return fmt.Errorf("data %s %q does not match record name %q", field.key, *field.data, props.Name)
+		}
+	}
+
+	// The name may be given relative to the zone or fully qualified, so it
+	// is only checked once the zone is known, and then made relative to it.
+	switch {
+	case d.Name == "":
+		d.Name = name
+	case props.zoneName != "":
+		if canonicalName(d.Name, props.zoneName) != canonicalName(name, props.zoneName) {
+			return fmt.Errorf("data name %q does not match record name %q", d.Name, props.Name)
+		}
+		d.Name = canonicalName(name, props.zoneName)
+	}
+
+	return syncPriority(&d.Priority, props)
+}
+
+// syncPriority syncs a priority carried in data with the record's priority.
+func syncPriority(priority **int, props *DNSRecordProperties) error {
+	switch {
+	case *priority == nil:
+		*priority = props.Priority
+	case props.Priority == nil:
+		value := **priority
+		props.Priority = &value
+	case **priority != *props.Priority:
+		return fmt.Errorf("data priority %d does not match priority %d", **priority, *props.Priority)
+	}
+	return nil
+}
+
+// splitSRVName splits an SRV record name into its service and protocol
+// labels and the remaining name, which is "@" for the apex.
+func splitSRVName(recordName string) (service, proto, name string, ok bool) {
+	labels := strings.SplitN(strings.ToLower(strings.TrimSuffix(recordName, ".")), ".", 3)
+	if len(labels) < 2 || !srvLabelPattern.MatchString(labels[0]) || !srvLabelPattern.MatchString(labels[1]) {
+		return "", "", "", false
+	}
+	name = "@"
+	if len(labels) == 3 {
+		name = labels[2]
+	}
+	return labels[0], labels[1], name, true
+}
+
+func (d *srvData) validate() error {
+	d.Service = strings.ToLower(d.Service)
+	d.Proto = strings.ToLower(d.Proto)
+	d.Name = strings.ToLower(strings.TrimSuffix(d.Name, "."))
+	if !srvLabelPattern.MatchString(d.Service) {
+		return fmt.Errorf("service must be a label starting with an underscore, got %q", d.Service)
+	}
+	if !srvLabelPattern.MatchString(d.Proto) {
+		return fmt.Errorf("proto must be a label starting with an underscore, got %q", d.Proto)
+	}
+	if d.Priority != nil && (*d.Priority < 0 || *d.Priority > 65535) {
+		return fmt.Errorf("priority must be between 0 and 65535, got %d", *d.Priority)
+	}
+	if d.Weight < 0 || d.Weight > 65535 {
+		return fmt.Errorf("weight must be between 0 and 65535, got %d", d.Weight)
+	}
+	if d.Port < 0 || d.Port > 65535 {
+		return fmt.Errorf("port must be between 0 and 65535, got %d", d.Port)
+	}
+	d.Target = strings.ToLower(d.Target)
+	if d.Target != "." {
+		d.Target = strings.TrimSuffix(d.Target, ".")
+	}
+	if d.Target == "" || strings.ContainsAny(d.Target, " \t\"") {
+		return fmt.Errorf("invalid target %q", d.Target)
+	}
+	return nil
+}
+
+func (d *srvData) content() string {
+	return fmt.Sprintf("%d %d %s", d.Weight, d.Port, d.Target)
+}
+
+func (d *srvData) cloudflareData() any {
+	data := map[string]any{
+		"weight": d.Weight,
+		"port":   d.Port,
+		"target": d.Target,
+	}
+	if d.Priority != nil {
+		data["priority"] = *d.Priority
+	}
+	return data
+}
+
+// =============================================================================
+// CAA
+// =============================================================================
+
+// caaTagPattern matches a CAA property tag: up to 15 letters and digits
+// (RFC 8659), stored lowercase.
+var caaTagPattern = regexp.MustCompile(`^[a-z0-9]{1,15}$`)
+
+// caaIssuerTags are the CAA property tags whose value is an issuer domain with
+// optional parameters (RFC 8659, RFC 9495). Values of other tags, except
+// iodef, are not checked beyond their quoting.
+var caaIssuerTags = map[string]bool{
+	"issue":     true,
+	"issuewild": true,
+	"issuemail": true,
+}
+
+// caaData is the data of CAA records.
+type caaData struct {
+	Flags int    `json:"flags"` // 128 marks the property critical
+	Tag   string `json:"tag"`   // e.g. issue, issuewild or iodef
+	Value string `json:"value"` // e.g. "letsencrypt.org" or "mailto:security@example.com"
+}
+
+// parseContent parses CAA content such as `0 issue "letsencrypt.org"`. The
+// value may be given with or without quotes.
+func (d *caaData) parseContent(content string) error {
+	flagsField, rest, _ := strings.Cut(strings.TrimSpace(content), " ")
+	tag, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	value = strings.TrimSpace(value)
+	if !ok || value == "" {
+		return fmt.Errorf("expected \"flags tag value\"")
+	}
+
+	flags, err := strconv.Atoi(flagsField)
+	if err != nil {
+		return fmt.Errorf("invalid flags %q", flagsField)
+	}
+	*d = caaData{Flags: flags, Tag: tag, Value: trimQuotes(value)}
+	return nil
+}
+
+func (d *caaData) validate() error {
+	if d.Flags < 0 || d.Flags > 255 {
+		return fmt.Errorf("flags must be between 0 and 255, got %d", d.Flags)
+	}
+	d.Tag = strings.ToLower(d.Tag)
+	if !caaTagPattern.MatchString(d.Tag) {
+		return fmt.Errorf("tag must be 1 to 15 letters and digits, got %q", d.Tag)
+	}
+	if strings.ContainsAny(d.Value, "\"\\") || strings.IndexFunc(d.Value, unicode.IsControl) >= 0 {
+		return fmt.Errorf("value must not contain quotes, backslashes or control characters")
+	}
+
+	switch {
+	case d.Tag == "iodef":
+		return validateIodefURL(d.Value)
+	case caaIssuerTags[d.Tag]:
+		return validateIssuerValue(d.Value)
+	}
+	return nil
+}
+
+// validateIodefURL checks the value of an iodef property, which must be a
+// mailto, http or https URL.
+func validateIodefURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("iodef value must be a URL: %w", err)
+	}
+	switch u.Scheme {
+	case "mailto":
+		if u.Opaque == "" || !strings.Contains(u.Opaque, "@") {
+			return fmt.Errorf("iodef mailto URL must contain an email address, got %q", value)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("iodef URL must contain a host, got %q", value)
+		}
+	default:
+		return fmt.Errorf("iodef value must be a mailto, http or https URL, got %q", value)
+	}
+	return nil
+}
+
+// validateIssuerValue checks the value of an issue, issuewild or issuemail
+// property: an optional issuer domain followed by optional "; key=value"
+// parameters. An empty issuer (e.g. ";") forbids issuance.
+func validateIssuerValue(value string) error {
+	issuer, params, _ := strings.Cut(value, ";")
+	issuer = strings.TrimSpace(issuer)
+	if issuer != "" && (strings.ContainsAny(issuer, " \t/:@") || strings.HasPrefix(issuer, ".") || strings.HasSuffix(issuer, ".")) {
+		return fmt.Errorf("invalid issuer domain %q", issuer)
+	}
+	for _, param := range strings.Split(params, ";") {
+		param = strings.TrimSpace(param)
+		if param == "" {
+			continue
+		}
+		if key, _, ok := strings.Cut(param, "="); !ok || key == "" {
+			return fmt.Errorf("invalid issuer parameter %q, expected key=value", param)
+		}
+	}
+	return nil
+}
+
+func (d *caaData) content() string {
+	return fmt.Sprintf(`%d %s "%s"`, d.Flags, d.Tag, d.Value)
+}
+
+func (d *caaData) cloudflareData() any {
+	return map[string]any{
+		"flags": d.Flags,
+		"tag":   d.Tag,
+		"value": d.Value,
+	}
+}
+
+// =============================================================================
+// TLSA, SMIMEA, SSHFP, CERT, DS and DNSKEY
+// =============================================================================
+
+// These record types share one content layout: a few numeric fields followed
+// by a hex or base64 blob, which zone files may split with whitespace.
+
+// parseNumericFields parses content of n numeric fields followed by a blob,
+// with the blob's whitespace removed.
+func parseNumericFields(content string, names ...string) ([]int, string, error) {
+	fields := strings.Fields(content)
+	if len(fields) <= len(names) {
+		return nil, "", fmt.Errorf("expected \"%s <data>\"", strings.Join(names, " "))
+	}
+
+	values := make([]int, len(names))
+	for i, name := range names {
+		value, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s %q", name, fields[i])
+		}
+		values[i] = value
+	}
+	return values, strings.Join(fields[len(names):], ""), nil
+}
+
+// checkRange reports a field outside [lo, hi].
+// roundSeconds rounds seconds to milliseconds, carrying a full minute into the
+// minutes and degrees, so 59.9996 seconds become 0 seconds of the next minute.
+func roundSeconds(degrees, minutes *int, seconds *float64) {
+	*seconds = math.Round(*seconds*1000) / 1000
+	if *seconds < 60 {
+		return
+	}
+	*seconds = 0
+	if *minutes++; *minutes == 60 {
+		*minutes = 0
+		*degrees++
+	}
+}
+
+func checkRange(name string, value, lo, hi int) error {
+	if value < lo || value > hi {
+		return fmt.Errorf("%s must be between %d and %d, got %d", name, lo, hi, value)
+	}
+	return nil
+}
+
+// canonicalHex validates a hex string and returns it in lowercase. If
+// digestLengths has an entry for digestType, the decoded length must match.
+func canonicalHex(name, value string, digestType int, digestLengths map[int]int) (string, error) {
+	decoded, err := hex.DecodeString(value)
+	if err != nil || len(decoded) == 0 {
+		return "", fmt.Errorf("%s must be a non-empty hex string", name)
+	}
+	if want, ok := digestLengths[digestType]; ok && len(decoded) != want {
+		return "", fmt.Errorf("%s must be %d bytes for type %d, got %d", name, want, digestType, len(decoded))
+	}
+	return strings.ToLower(value), nil
+}
+
+// canonicalBase64 validates a base64 string, ignoring whitespace.
+func canonicalBase64(name, value string) (string, error) {
+	value = strings.Join(strings.Fields(value), "")
+	if decoded, err := base64.StdEncoding.DecodeString(value); err != nil || len(decoded) == 0 {
+		return "", fmt.Errorf("%s must be a non-empty base64 string", name)
+	}
+	return value, nil
+}
+
+// tlsaDigestLengths maps TLSA matching types to their digest length:
+// SHA-256 and SHA-512.
+var tlsaDigestLengths = map[int]int{1: 32, 2: 64}
+
+// sshfpDigestLengths maps SSHFP fingerprint types to their digest length:
+// SHA-1 and SHA-256.
+var sshfpDigestLengths = map[int]int{1: 20, 2: 32}
+
+// dsDigestLengths maps DS digest types to their digest length: SHA-1,
+// SHA-256, GOST R 34.11-94 and SHA-384.
+var dsDigestLengths = map[int]int{1: 20, 2: 32, 3: 32, 4: 48}
+
+// tlsaData is the data of TLSA (RFC 6698) and SMIMEA (RFC 8162) records.
+type tlsaData struct {
+	Usage        int    `json:"usage"`         // 0-3, e.g. 3 for DANE-EE
+	Selector     int    `json:"selector"`      // 0 full certificate, 1 public key
+	MatchingType int    `json:"matching_type"` // 0 exact, 1 SHA-256, 2 SHA-512
+	Certificate  string `json:"certificate"`   // hex
+}
+
+func (d *tlsaData) parseContent(content string) error {
+	values, blob, err := parseNumericFields(content, "usage", "selector", "matching_type")
+	if err != nil {
+		return err
+	}
+	*d = tlsaData{Usage: values[0], Selector: values[1], MatchingType: values[2], Certificate: blob}
+	return nil
+}
+
+func (d *tlsaData) validate() error {
+	if err := errors.Join(
+		checkRange("usage", d.Usage, 0, 3),
+		checkRange("selector", d.Selector, 0, 1),
+		checkRange("matching_type", d.MatchingType, 0, 2),
+	); err != nil {
+		return err
+	}
+	certificate, err := canonicalHex("certificate", d.Certificate, d.MatchingType, tlsaDigestLengths)
+	if err != nil {
+		return err
+	}
+	d.Certificate = certificate
+	return nil
+}
+
+func (d *tlsaData) content() string {
+	return fmt.Sprintf("%d %d %d %s", d.Usage, d.Selector, d.MatchingType, d.Certificate)
+}
+
+func (d *tlsaData) cloudflareData() any {
+	return map[string]any{
+		"usage":         d.Usage,
+		"selector":      d.Selector,
+		"matching_type": d.MatchingType,
+		"certificate":   d.Certificate,
+	}
+}
+
+// sshfpData is the data of SSHFP records (RFC 4255).
+type sshfpData struct {
+	Algorithm   int    `json:"algorithm"`   // e.g. 4 for Ed25519
+	Type        int    `json:"type"`        // 1 SHA-1, 2 SHA-256
+	Fingerprint string `json:"fingerprint"` // hex
+}
+
+func (d *sshfpData) parseContent(content string) error {
+	values, blob, err := parseNumericFields(content, "algorithm", "type")
+	if err != nil {
+		return err
+	}
+	*d = sshfpData{Algorithm: values[0], Type: values[1], Fingerprint: blob}
+	return nil
+}
+
+func (d *sshfpData) validate() error {
+	if err := errors.Join(
+		checkRange("algorithm", d.Algorithm, 0, 255),
+		checkRange("type", d.Type, 0, 255),
+	); err != nil {
+		return err
+	}
+	fingerprint, err := canonicalHex("fingerprint", d.Fingerprint, d.Type, sshfpDigestLengths)
+	if err != nil {
+		return err
+	}
+	d.Fingerprint = fingerprint
+	return nil
+}
+
+func (d *sshfpData) content() string {
+	return fmt.Sprintf("%d %d %s", d.Algorithm, d.Type, d.Fingerprint)
+}
+
+func (d *sshfpData) cloudflareData() any {
+	return map[string]any{
+		"algorithm":   d.Algorithm,
+		"type":        d.Type,
+		"fingerprint": d.Fingerprint,
+	}
+}
+
+// certData is the data of CERT records (RFC 4398).
+type certData struct {
+	Type        int    `json:"type"`        // e.g. 1 for PKIX
+	KeyTag      int    `json:"key_tag"`     // key tag of the certificate's key
+	Algorithm   int    `json:"algorithm"`   // DNSSEC algorithm number
+	Certificate string `json:"certificate"` // base64
+}
+
+func (d *certData) parseContent(content string) error {
+	values, blob, err := parseNumericFields(content, "type", "key_tag", "algorithm")
+	if err != nil {
+		return err
+	}
+	*d = certData{Type: values[0], KeyTag: values[1], Algorithm: values[2], Certificate: blob}
+	return nil
+}
+
+func (d *certData) validate() error {
+	if err := errors.Join(
+		checkRange("type", d.Type, 0, 65535),
+		checkRange("key_tag", d.KeyTag, 0, 65535),
+		checkRange("algorithm", d.Algorithm, 0, 255),
+	); err != nil {
+		return err
+	}
+	certificate, err := canonicalBase64("certificate", d.Certificate)
+	if err != nil {
+		return err
+	}
+	d.Certificate = certificate
+	return nil
+}
+
+func (d *certData) content() string {
+	return fmt.Sprintf("%d %d %d %s", d.Type, d.KeyTag, d.Algorithm, d.Certificate)
+}
+
+func (d *certData) cloudflareData() any {
+	return map[string]any{
+		"type":        d.Type,
+		"key_tag":     d.KeyTag,
+		"algorithm":   d.Algorithm,
+		"certificate": d.Certificate,
+	}
+}
+
+// dsData is the data of DS records (RFC 4034).
+type dsData struct {
+	KeyTag     int    `json:"key_tag"`     // key tag of the referenced DNSKEY
+	Algorithm  int    `json:"algorithm"`   // DNSSEC algorithm number, e.g. 13
+	DigestType int    `json:"digest_type"` // 1 SHA-1, 2 SHA-256, 4 SHA-384
+	Digest     string `json:"digest"`      // hex
+}
+
+func (d *dsData) parseContent(content string) error {
+	values, blob, err := parseNumericFields(content, "key_tag", "algorithm", "digest_type")
+	if err != nil {
+		return err
+	}
+	*d = dsData{KeyTag: values[0], Algorithm: values[1], DigestType: values[2], Digest: blob}
+	return nil
+}
+
+func (d *dsData) validate() error {
+	if err := errors.Join(
+		checkRange("key_tag", d.KeyTag, 0, 65535),
+		checkRange("algorithm", d.Algorithm, 0, 255),
+		checkRange("digest_type", d.DigestType, 0, 255),
+	); err != nil {
+		return err
+	}
+	digest, err := canonicalHex("digest", d.Digest, d.DigestType, dsDigestLengths)
+	if err != nil {
+		return err
+	}
+	d.Digest = digest
+	return nil
+}
+
+func (d *dsData) content() string {
+	return fmt.Sprintf("%d %d %d %s", d.KeyTag, d.Algorithm, d.DigestType, d.Digest)
+}
+
+func (d *dsData) cloudflareData() any {
+	return map[string]any{
+		"key_tag":     d.KeyTag,
+		"algorithm":   d.Algorithm,
+		"digest_type": d.DigestType,
+		"digest":      d.Digest,
+	}
+}
+
+// dnskeyData is the data of DNSKEY records (RFC 4034).
+type dnskeyData struct {
+	Flags     int    `json:"flags"`      // 256 ZSK, 257 KSK
+	Protocol  int    `json:"protocol"`   // always 3
+	Algorithm int    `json:"algorithm"`  // DNSSEC algorithm number, e.g. 13
+	PublicKey string `json:"public_key"` // base64
+}
+
+func (d *dnskeyData) parseContent(content string) error {
+	values, blob, err := parseNumericFields(content, "flags", "protocol", "algorithm")
+	if err != nil {
+		return err
+	}
+	*d = dnskeyData{Flags: values[0], Protocol: values[1], Algorithm: values[2], PublicKey: blob}
+	return nil
+}
+
+func (d *dnskeyData) validate() error {
+	if err := errors.Join(
+		checkRange("flags", d.Flags, 0, 65535),
+		checkRange("algorithm", d.Algorithm, 0, 255),
+	); err != nil {
+		return err
+	}
+	if d.Protocol != 3 {
+		return fmt.Errorf("protocol must be 3, got %d", d.Protocol)
+	}
+	publicKey, err := canonicalBase64("public_key", d.PublicKey)
+	if err != nil {
+		return err
+	}
+	d.PublicKey = publicKey
+	return nil
+}
+
+func (d *dnskeyData) content() string {
+	return fmt.Sprintf("%d %d %d %s", d.Flags, d.Protocol, d.Algorithm, d.PublicKey)
+}
+
+func (d *dnskeyData) cloudflareData() any {
+	return map[string]any{
+		"flags":      d.Flags,
+		"protocol":   d.Protocol,
+		"algorithm":  d.Algorithm,
+		"public_key": d.PublicKey,
+	}
+}
+
+// =============================================================================
+// LOC
+// =============================================================================
+
+// LOC limits in meters (RFC 1876).
+const (
+	locMinAltitude  = -100000.00
+	locMaxAltitude  = 42849672.95
+	locMaxPrecision = 90000000.00
+)
+
+// locData is the data of LOC records (RFC 1876). Size and precisions default
+// to 1m, 10000m and 10m if omitted from content or data.
+type locData struct {
+	LatDegrees    int     `json:"lat_degrees"`    // 0-90
+	LatMinutes    int     `json:"lat_minutes"`    // 0-59
+	LatSeconds    float64 `json:"lat_seconds"`    // 0-59.999
+	LatDirection  string  `json:"lat_direction"`  // N or S
+	LongDegrees   int     `json:"long_degrees"`   // 0-180
+	LongMinutes   int     `json:"long_minutes"`   // 0-59
+	LongSeconds   float64 `json:"long_seconds"`   // 0-59.999
+	LongDirection string  `json:"long_direction"` // E or W
+	Altitude      float64 `json:"altitude"`       // meters
+	Size          float64 `json:"size"`           // diameter of the sphere, meters
+	PrecisionHorz float64 `json:"precision_horz"` // meters
+	PrecisionVert float64 `json:"precision_vert"` // meters
+}
+
+func (d *locData) setDefaults() {
+	*d = locData{Size: 1, PrecisionHorz: 10000, PrecisionVert: 10}
+}
+
+// parseContent parses LOC content such as
+// "51 30 12.748 N 0 7 39.611 W 0.00m 1m 10000m 10m". Minutes, seconds, size
+// and precisions are optional.
+func (d *locData) parseContent(content string) error {
+	fields := strings.Fields(content)
+
+	d.setDefaults()
+	var err error
+	if d.LatDegrees, d.LatMinutes, d.LatSeconds, d.LatDirection, fields, err = parseLOCCoordinate(fields, "N", "S"); err != nil {
+		return fmt.Errorf("invalid latitude: %w", err)
+	}
+	if d.LongDegrees, d.LongMinutes, d.LongSeconds, d.LongDirection, fields, err = parseLOCCoordinate(fields, "E", "W"); err != nil {
+		return fmt.Errorf("invalid longitude: %w", err)
+	}
+	if len(fields) == 0 || len(fields) > 4 {
+		return fmt.Errorf("expected \"altitude [size [precision_horz [precision_vert]]]\" after the coordinates")
+	}
+
+	meters := []*float64{&d.Altitude, &d.Size, &d.PrecisionHorz, &d.PrecisionVert}
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSuffix(field, "m"), 64)
+		if err != nil {
+			return fmt.Errorf("invalid distance %q", field)
+		}
+		*meters[i] = value
+	}
+	return nil
+}
+
+// parseLOCCoordinate parses "degrees [minutes [seconds]] direction" from the
+// start of fields and returns the remaining fields.
+func parseLOCCoordinate(fields []string, directions ...string) (degrees, minutes int, seconds float64, direction string, rest []string, err error) {
+	var numbers []string
+	for i, field := range fields {
+		if slices.Contains(directions, strings.ToUpper(field)) {
+			direction, rest = strings.ToUpper(field), fields[i+1:]
+			break
+		}
+		numbers = append(numbers, field)
+	}
+	if direction == "" || len(numbers) == 0 || len(numbers) > 3 {
+		return 0, 0, 0, "", nil, fmt.Errorf("expected \"degrees [minutes [seconds]] %s\"", strings.Join(directions, "|"))
+	}
+
+	if degrees, err = strconv.Atoi(numbers[0]); err != nil {
+		return 0, 0, 0, "", nil, fmt.Errorf("invalid degrees %q", numbers[0])
+	}
+	if len(numbers) > 1 {
+		if minutes, err = strconv.Atoi(numbers[1]); err != nil {
+			return 0, 0, 0, "", nil, fmt.Errorf("invalid minutes %q", numbers[1])
+		}
+	}
+	if len(numbers) > 2 {
+		if seconds, err = strconv.ParseFloat(numbers[2], 64); err != nil {
+			return 0, 0, 0, "", nil, fmt.Errorf("invalid seconds %q", numbers[2])
+		}
+	}
+	return degrees, minutes, seconds, direction, rest, nil
+}
+
+func (d *locData) validate() error {
+	d.LatDirection = strings.ToUpper(d.LatDirection)
+	d.LongDirection = strings.ToUpper(d.LongDirection)
+	if d.LatDirection != "N" && d.LatDirection != "S" {
+		return fmt.Errorf("lat_direction must be N or S, got %q", d.LatDirection)
+	}
+	if d.LongDirection != "E" && d.LongDirection != "W" {
+		return fmt.Errorf("long_direction must be E or W, got %q", d.LongDirection)
+	}
+
+	if err := errors.Join(
+		checkRange("lat_degrees", d.LatDegrees, 0, 90),
+		checkRange("lat_minutes", d.LatMinutes, 0, 59),
+		checkRange("long_degrees", d.LongDegrees, 0, 180),
+		checkRange("long_minutes", d.LongMinutes, 0, 59),
+	); err != nil {
+		return err
+	}
+	if d.LatSeconds < 0 || d.LatSeconds >= 60 || d.LongSeconds < 0 || d.LongSeconds >= 60 {
+		return fmt.Errorf("lat_seconds and long_seconds must be at least 0 and less than 60")
+	}
+
+	// Seconds are stored with millisecond precision, distances in centimeters.
+	roundSeconds(&d.LatDegrees, &d.LatMinutes, &d.LatSeconds)
+	roundSeconds(&d.LongDegrees, &d.LongMinutes, &d.LongSeconds)
+	for _, meters := range []*float64{&d.Altitude, &d.Size, &d.PrecisionHorz, &d.PrecisionVert} {
+		*meters = math.Round(*meters*100) / 100
+	}
+
+	if (d.LatDegrees == 90 && (d.LatMinutes > 0 || d.LatSeconds > 0)) || (d.LongDegrees == 180 && (d.LongMinutes > 0 || d.LongSeconds > 0)) {
+		return fmt.Errorf("coordinates must not exceed 90 degrees latitude and 180 degrees longitude")
+	}
+	if d.Altitude < locMinAltitude || d.Altitude > locMaxAltitude {
+		return fmt.Errorf("altitude must be between %.2f and %.2f meters, got %.2f", locMinAltitude, locMaxAltitude, d.Altitude)
+	}
+	for name, meters := range map[string]float64{"size": d.Size, "precision_horz": d.PrecisionHorz, "precision_vert": d.PrecisionVert} {
+		if meters < 0 || meters > locMaxPrecision {
+			return fmt.Errorf("%s must be between 0 and %.2f meters, got %.2f", name, locMaxPrecision, meters)
+		}
+	}
+	return nil
+}
+
+func (d *locData) content() string {
+	return fmt.Sprintf("%d %d %.3f %s %d %d %.3f %s %.2fm %.2fm %.2fm %.2fm",
+		d.LatDegrees, d.LatMinutes, d.LatSeconds, d.LatDirection,
+		d.LongDegrees, d.LongMinutes, d.LongSeconds, d.LongDirection,
+		d.Altitude, d.Size, d.PrecisionHorz, d.PrecisionVert)
+}
+
+func (d *locData) cloudflareData() any {
+	return map[string]any{
+		"lat_degrees":    d.LatDegrees,
+		"lat_minutes":    d.LatMinutes,
+		"lat_seconds":    d.LatSeconds,
+		"lat_direction":  d.LatDirection,
+		"long_degrees":   d.LongDegrees,
+		"long_minutes":   d.LongMinutes,
+		"long_seconds":   d.LongSeconds,
+		"long_direction": d.LongDirection,
+		"altitude":       d.Altitude,
+		"size":           d.Size,
+		"precision_horz": d.PrecisionHorz,
+		"precision_vert": d.PrecisionVert,
+	}
+}
+
+// =============================================================================
+// NAPTR
+// =============================================================================
+
+// naptrFlagsPattern matches NAPTR flags, e.g. "S", "A", "U" or "P".
+var naptrFlagsPattern = regexp.MustCompile(`^[A-Za-z0-9]*$`)
+
+// naptrData is the data of NAPTR records (RFC 3403).
+type naptrData struct {
+	Order       int    `json:"order"`       // processing order, lower first
+	Preference  int    `json:"preference"`  // preference among equal orders
+	Flags       string `json:"flags"`       // e.g. "S" or "U"
+	Service     string `json:"service"`     // e.g. "SIP+D2U"
+	Regex       string `json:"regex"`       // substitution expression, or empty
+	Replacement string `json:"replacement"` // next name to query, or "."
+}
+
+// parseContent parses NAPTR content such as
+// `100 10 "S" "SIP+D2U" "" _sip._udp.example.com`.
+func (d *naptrData) parseContent(content string) error {
+	fields, err := splitQuoted(strings.Join(strings.Fields(content), " "))
+	if err != nil {
+		return err
+	}
+	if len(fields) != 6 {
+		return fmt.Errorf("expected \"order preference flags service regex replacement\"")
+	}
+
+	order, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("invalid order %q", fields[0])
+	}
+	preference, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("invalid preference %q", fields[1])
+	}
+	*d = naptrData{
+		Order:       order,
+		Preference:  preference,
+		Flags:       trimQuotes(fields[2]),
+		Service:     trimQuotes(fields[3]),
+		Regex:       trimQuotes(fields[4]),
+		Replacement: fields[5],
+	}
+	return nil
+}
+
+func (d *naptrData) validate() error {
+	if err := errors.Join(
+		checkRange("order", d.Order, 0, 65535),
+		checkRange("preference", d.Preference, 0, 65535),
+	); err != nil {
+		return err
+	}
+	if !naptrFlagsPattern.MatchString(d.Flags) {
+		return fmt.Errorf("flags must consist of letters and digits, got %q", d.Flags)
+	}
+	d.Flags = strings.ToUpper(d.Flags)
+	if strings.ContainsAny(d.Service, "\" \t") {
+		return fmt.Errorf("invalid service %q", d.Service)
+	}
+	if strings.Contains(d.Regex, `"`) {
+		return fmt.Errorf("regex must not contain quotes")
+	}
+
+	d.Replacement = strings.ToLower(d.Replacement)
+	if d.Replacement != "." {
+		d.Replacement = strings.TrimSuffix(d.Replacement, ".")
+	}
+	if d.Replacement == "" || strings.ContainsAny(d.Replacement, " \t\"") {
+		return fmt.Errorf("invalid replacement %q", d.Replacement)
+	}
+	if d.Regex != "" && d.Replacement != "." {
+		return fmt.Errorf("regex and replacement are mutually exclusive; set replacement to \".\" when using regex")
+	}
+	return nil
+}
+
+func (d *naptrData) content() string {
+	return fmt.Sprintf(`%d %d "%s" "%s" "%s" %s`, d.Order, d.Preference, d.Flags, d.Service, d.Regex, d.Replacement)
+}
+
+func (d *naptrData) cloudflareData() any {
+	return map[string]any{
+		"order":       d.Order,
+		"preference":  d.Preference,
+		"flags":       d.Flags,
+		"service":     d.Service,
+		"regex":       d.Regex,
+		"replacement": d.Replacement,
+	}
+}
+
+// =============================================================================
+// URI
+// =============================================================================
+
+// uriData is the data of URI records (RFC 7553). The priority is the
+// record's priority and is filled in from the record if omitted.
+type uriData struct {
+	Priority *int   `json:"priority"` // lower is preferred
+	Weight   int    `json:"weight"`   // relative weight among equal priorities
+	Target   string `json:"target"`   // absolute URI
+}
+
+// parseContent parses URI content, which Cloudflare stores without the
+// priority: `weight "target"`.
+func (d *uriData) parseContent(content string) error {
+	weightField, target, ok := strings.Cut(strings.TrimSpace(content), " ")
+	target = trimQuotes(strings.TrimSpace(target))
+	if !ok || target == "" {
+		return fmt.Errorf("expected \"weight target\"")
+	}
+
+	weight, err := strconv.Atoi(weightField)
+	if err != nil {
+		return fmt.Errorf("invalid weight %q", weightField)
+	}
+	*d = uriData{Weight: weight, Target: target}
+	return nil
+}
+
+func (d *uriData) syncFields(props *DNSRecordProperties) error {
+	return syncPriority(&d.Priority, props)
+}
+
+func (d *uriData) validate() error {
+	if d.Priority != nil {
+		if err := checkRange("priority", *d.Priority, 0, 65535); err != nil {
+			return err
+		}
+	}
+	if err := checkRange("weight", d.Weight, 0, 65535); err != nil {
+		return err
+	}
+	if u, err := url.Parse(d.Target); err != nil || u.Scheme == "" || strings.ContainsAny(d.Target, "\" \t") {
+		return fmt.Errorf("target must be an absolute URI, got %q", d.Target)
+	}
+	return nil
+}
+
+func (d *uriData) content() string {
+	return fmt.Sprintf(`%d "%s"`, d.Weight, d.Target)
+}
+
+func (d *uriData) cloudflareData() any {
+	return map[string]any{
+		"weight": d.Weight,
+		"target": d.Target,
+	}
+}
+
+// trimQuotes removes one pair of surrounding double quotes.
+func trimQuotes(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,605 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// HTTPS and SVCB Tests
+// =============================================================================
+
+func TestNormalizeRecordData_SVCBContent(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+		data     string
+	}{
+		{
+			content:  `1 . alpn="h3,h2"`,
+			expected: `1 . alpn="h3,h2"`,
+			data:     `{"priority":1,"target":".","alpn":["h3","h2"]}`,
+		},
+		{
+			content:  `0 Svc.Example.com.`,
+			expected: `0 svc.example.com`,
+			data:     `{"priority":0,"target":"svc.example.com"}`,
+		},
+		{
+			content:  `2 svc.example.com ipv6hint=2001:DB8:0::1 port=8443 ipv4hint="192.0.2.1,192.0.2.2"`,
+			expected: `2 svc.example.com port="8443" ipv4hint="192.0.2.1,192.0.2.2" ipv6hint="2001:db8::1"`,
+			data:     `{"priority":2,"target":"svc.example.com","port":8443,"ipv4hint":["192.0.2.1","192.0.2.2"],"ipv6hint":["2001:db8::1"]}`,
+		},
+		{
+			content:  `1 . no-default-alpn alpn=h3 mandatory=ipv4hint,alpn ipv4hint=192.0.2.1`,
+			expected: `1 . mandatory="alpn,ipv4hint" alpn="h3" no-default-alpn ipv4hint="192.0.2.1"`,
+			data:     `{"priority":1,"target":".","mandatory":["alpn","ipv4hint"],"alpn":["h3"],"no_default_alpn":true,"ipv4hint":["192.0.2.1"]}`,
+		},
+		{
+			content:  `1 dns.example.com alpn=h2 dohpath=/dns-query{?dns} ohttp`,
+			expected: `1 dns.example.com alpn="h2" dohpath="/dns-query{?dns}" ohttp`,
+			data:     `{"priority":1,"target":"dns.example.com","alpn":["h2"],"dohpath":"/dns-query{?dns}","ohttp":true}`,
+		},
+		{
+			content:  `1 . key65001 KEY9="x" key3=443 mandatory=key9`,
+			expected: `1 . mandatory="key9" port="443" key9="x" key65001`,
+			data:     `{"priority":1,"target":".","mandatory":["key9"],"port":443,"params":{"key65001":"","key9":"x"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "HTTPS", Name: "www", Content: tt.content}
+			if err := normalizeRecordData(props); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Content != tt.expected {
+				t.Errorf("expected content %q, got %q", tt.expected, props.Content)
+			}
+			if string(props.Data) != tt.data {
+				t.Errorf("expected data %s, got %s", tt.data, props.Data)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecordData_SVCBData(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "SVCB",
+		Name:       "_dns",
+		Data:       json.RawMessage(`{"priority": 1, "target": "dns.example.com.", "alpn": ["dot"], "port": 853}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != `1 dns.example.com alpn="dot" port="853"` {
+		t.Errorf("unexpected content %q", props.Content)
+	}
+
+	data := cloudflareRecordData(props).(map[string]any)
+	if data["priority"] != 1 || data["target"] != "dns.example.com" || data["value"] != `alpn="dot" port="853"` {
+		t.Errorf("unexpected Cloudflare data %v", data)
+	}
+}
+
+func TestNormalizeRecordData_ContentMatchingData(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "HTTPS",
+		Name:       "www",
+		Content:    `1 . alpn=h2`,
+		Data:       json.RawMessage(`{"priority": 1, "target": ".", "alpn": ["h2"]}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error for equivalent content: %v", err)
+	}
+	if props.Content != `1 . alpn="h2"` {
+		t.Errorf("expected canonical content, got %q", props.Content)
+	}
+}
+
+func TestNormalizeRecordData_SVCBInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		data     string
+		expected string
+	}{
+		{"content mismatch", `1 . alpn="h3"`, `{"priority": 1, "target": ".", "alpn": ["h2"]}`, "does not match data"},
+		{"alias mode params", `0 . alpn="h2"`, "", "AliasMode"},
+		{"invalid ipv4hint", `1 . ipv4hint="2001:db8::1"`, "", "invalid ipv4hint"},
+		{"invalid ech", "", `{"priority": 1, "target": ".", "ech": "not base64!"}`, "ech must be base64"},
+		{"unknown param", `1 . foo="bar"`, "", "unsupported SvcParam"},
+		{"reserved key", `1 . key65535`, "", "reserved"},
+		{"missing mandatory key", `1 . mandatory="alpn" port=443`, "", "mandatory key alpn is not set"},
+		{"mandatory lists itself", `1 . mandatory="mandatory"`, "", "must not list itself"},
+		{"no-default-alpn without alpn", `1 . no-default-alpn`, "", "no_default_alpn requires alpn"},
+		{"registered key in params", "", `{"priority": 1, "target": ".", "params": {"key3": "443"}}`, "set port instead of key3"},
+		{"invalid dohpath", `1 . dohpath="https://dns.example.com/"`, "", "dohpath must be a relative URI template"},
+		{"unknown field", "", `{"priority": 1, "target": ".", "alpns": ["h2"]}`, "unknown field"},
+		{"missing target", `1`, "", "expected \"priority target [params]\""},
+		{"unterminated quote", `1 . alpn="h2`, "", "unterminated quote"},
+		{"port out of range", "", `{"priority": 1, "target": ".", "port": 70000}`, "port must be between 1 and 65535"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "HTTPS", Name: "www", Content: tt.content}
+			if tt.data != "" {
+				props.Data = json.RawMessage(tt.data)
+			}
+
+			err := normalizeRecordData(props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestNormalizeRecordData_UnstructuredType(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "A",
+		Name:       "www",
+		Content:    "192.0.2.1",
+		Data:       json.RawMessage(`{"address": "192.0.2.1"}`),
+	}
+
+	if err := normalizeRecordData(props); err == nil {
+		t.Fatal("expected error for data on an A record, got nil")
+	}
+}
+
+func TestValidateProperties_HTTPSRejectsPriority(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "HTTPS",
+		Name:       "www",
+		Content:    `1 . alpn="h2"`,
+		Priority:   intPtr(1),
+	}
+
+	if err := validateProperties(props); err == nil {
+		t.Fatal("expected error for priority on an HTTPS record, got nil")
+	}
+}
+
+// =============================================================================
+// SRV Tests
+// =============================================================================
+
+func TestNormalizeRecordData_SRVContent(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "SRV",
+		Name:       "_SIP._tcp.eu",
+		Content:    "5  5060 SIPServer.example.com.",
+		Priority:   intPtr(10),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != "5 5060 sipserver.example.com" {
+		t.Errorf("expected canonical content, got %q", props.Content)
+	}
+	expected := `{"service":"_sip","proto":"_tcp","name":"eu","priority":10,"weight":5,"port":5060,"target":"sipserver.example.com"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected data %s, got %s", expected, props.Data)
+	}
+}
+
+func TestNormalizeRecordData_SRVData(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "SRV",
+		Name:       "_xmpp-server._tcp",
+		Data:       json.RawMessage(`{"priority": 20, "weight": 0, "port": 5269, "target": "xmpp.example.com"}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != "0 5269 xmpp.example.com" {
+		t.Errorf("expected content derived from data, got %q", props.Content)
+	}
+	if props.Priority == nil || *props.Priority != 20 {
+		t.Error("expected Priority 20 taken from data")
+	}
+	expected := `{"service":"_xmpp-server","proto":"_tcp","name":"@","priority":20,"weight":0,"port":5269,"target":"xmpp.example.com"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected data %s, got %s", expected, props.Data)
+	}
+}
+
+func TestNormalizeRecordData_SRVInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordName string
+		content    string
+		data       string
+		priority   *int
+		expected   string
+	}{
+		{"name without service", "sip.example.com", "5 5060 sip.example.com", "", intPtr(10), "must start with _service._proto"},
+		{"service mismatch", "_sip._tcp", "", `{"service": "_sips", "priority": 1, "weight": 5, "port": 5061, "target": "sip.example.com"}`, nil, "does not match record name"},
+		{"priority mismatch", "_sip._tcp", "", `{"priority": 1, "weight": 5, "port": 5060, "target": "sip.example.com"}`, intPtr(2), "does not match priority"},
+		{"port out of range", "_sip._tcp", "5 70000 sip.example.com", "", intPtr(10), "port must be between 0 and 65535"},
+		{"missing target", "_sip._tcp", "5 5060", "", intPtr(10), "expected \"weight port target\""},
+		{"content mismatch", "_sip._tcp", "5 5060 sip.example.com", `{"weight": 5, "port": 5061, "target": "sip.example.com"}`, intPtr(10), "does not match data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "SRV", Name: tt.recordName, Content: tt.content, Priority: tt.priority}
+			if tt.data != "" {
+				props.Data = json.RawMessage(tt.data)
+			}
+
+			err := normalizeRecordData(props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+// =============================================================================
+// CAA Tests
+// =============================================================================
+
+func TestNormalizeRecordData_CAAContent(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{`0 issue "letsencrypt.org"`, `0 issue "letsencrypt.org"`},
+		{`0  ISSUE letsencrypt.org`, `0 issue "letsencrypt.org"`},
+		{`128 issuewild ";"`, `128 issuewild ";"`},
+		{`0 issue "ca.example.net; account=230123"`, `0 issue "ca.example.net; account=230123"`},
+		{`0 iodef "mailto:security@example.com"`, `0 iodef "mailto:security@example.com"`},
+		{`0 issuemail "ca.example"`, `0 issuemail "ca.example"`},
+		{`0 ContactEmail "domains@example.com"`, `0 contactemail "domains@example.com"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "CAA", Name: "@", Content: tt.content}
+			if err := normalizeRecordData(props); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Content != tt.expected {
+				t.Errorf("expected content %q, got %q", tt.expected, props.Content)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecordData_CAAData(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "CAA",
+		Name:       "@",
+		Data:       json.RawMessage(`{"flags": 0, "tag": "iodef", "value": "https://iodef.example.com/report"}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != `0 iodef "https://iodef.example.com/report"` {
+		t.Errorf("unexpected content %q", props.Content)
+	}
+}
+
+func TestNormalizeRecordData_CAAInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"invalid tag", `0 issue-wild "letsencrypt.org"`, "tag must be 1 to 15 letters and digits"},
+		{"issuemail url", `0 issuemail "https://ca.example"`, "invalid issuer domain"},
+		{"flags out of range", `256 issue "letsencrypt.org"`, "flags must be between 0 and 255"},
+		{"iodef scheme", `0 iodef "ftp://example.com"`, "must be a mailto, http or https URL"},
+		{"iodef mailto", `0 iodef "mailto:"`, "must contain an email address"},
+		{"iodef host", `0 iodef "https://"`, "must contain a host"},
+		{"issuer url", `0 issue "https://letsencrypt.org"`, "invalid issuer domain"},
+		{"issuer parameter", `0 issue "letsencrypt.org; account"`, "invalid issuer parameter"},
+		{"missing value", `0 issue`, "expected \"flags tag value\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "CAA", Name: "@", Content: tt.content}
+
+			err := normalizeRecordData(props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+// =============================================================================
+// TLSA, SMIMEA, SSHFP, CERT, DS and DNSKEY Tests
+// =============================================================================
+
+const (
+	testSHA1   = "0123456789abcdef0123456789abcdef01234567"
+	testSHA256 = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+func TestNormalizeRecordData_SecurityTypes(t *testing.T) {
+	tests := []struct {
+		recordType string
+		content    string
+		expected   string
+		data       string
+	}{
+		{
+			recordType: "TLSA",
+			content:    "3 1 1 " + strings.ToUpper(testSHA256[:32]) + " " + testSHA256[32:],
+			expected:   "3 1 1 " + testSHA256,
+			data:       `{"usage":3,"selector":1,"matching_type":1,"certificate":"` + testSHA256 + `"}`,
+		},
+		{
+			recordType: "SMIMEA",
+			content:    "3 0 0 3082",
+			expected:   "3 0 0 3082",
+			data:       `{"usage":3,"selector":0,"matching_type":0,"certificate":"3082"}`,
+		},
+		{
+			recordType: "SSHFP",
+			content:    "4 2 " + testSHA256,
+			expected:   "4 2 " + testSHA256,
+			data:       `{"algorithm":4,"type":2,"fingerprint":"` + testSHA256 + `"}`,
+		},
+		{
+			recordType: "CERT",
+			content:    "1 12345 8 MIIB IjAN",
+			expected:   "1 12345 8 MIIBIjAN",
+			data:       `{"type":1,"key_tag":12345,"algorithm":8,"certificate":"MIIBIjAN"}`,
+		},
+		{
+			recordType: "DS",
+			content:    "2371 13 1 " + testSHA1,
+			expected:   "2371 13 1 " + testSHA1,
+			data:       `{"key_tag":2371,"algorithm":13,"digest_type":1,"digest":"` + testSHA1 + `"}`,
+		},
+		{
+			recordType: "DNSKEY",
+			content:    "257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0d xCjjnopKl+GqJxpVXckHAeF+KkxLbxIL fDLUT0rAK9iUzy1L53eKGQ==",
+			expected:   "257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==",
+			data:       `{"flags":257,"protocol":3,"algorithm":13,"public_key":"mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ=="}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: tt.recordType, Name: "_443._tcp.www", Content: tt.content}
+			if err := normalizeRecordData(props); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Content != tt.expected {
+				t.Errorf("expected content %q, got %q", tt.expected, props.Content)
+			}
+			if string(props.Data) != tt.data {
+				t.Errorf("expected data %s, got %s", tt.data, props.Data)
+			}
+
+			// The data form must produce the same record.
+			fromData := &DNSRecordProperties{RecordType: tt.recordType, Name: "_443._tcp.www", Data: props.Data}
+			if err := normalizeRecordData(fromData); err != nil {
+				t.Fatalf("unexpected error for data: %v", err)
+			}
+			if fromData.Content != tt.expected {
+				t.Errorf("expected content %q from data, got %q", tt.expected, fromData.Content)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecordData_SecurityTypesInvalid(t *testing.T) {
+	tests := []struct {
+		recordType string
+		content    string
+		expected   string
+	}{
+		{"TLSA", "4 1 1 " + testSHA256, "usage must be between 0 and 3"},
+		{"TLSA", "3 1 1 " + testSHA1, "certificate must be 32 bytes for type 1, got 20"},
+		{"TLSA", "3 1 1 not-hex", "certificate must be a non-empty hex string"},
+		{"TLSA", "3 1 1", "expected \"usage selector matching_type <data>\""},
+		{"SSHFP", "4 1 " + testSHA256, "fingerprint must be 20 bytes for type 1, got 32"},
+		{"SSHFP", "four 2 " + testSHA256, "invalid algorithm \"four\""},
+		{"CERT", "1 12345 8 not*base64", "certificate must be a non-empty base64 string"},
+		{"DS", "70000 13 2 " + testSHA256, "key_tag must be between 0 and 65535"},
+		{"DS", "2371 13 2 " + testSHA1, "digest must be 32 bytes for type 2, got 20"},
+		{"DNSKEY", "257 2 13 AwEAAQ==", "protocol must be 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType+" "+tt.expected, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: tt.recordType, Name: "www", Content: tt.content}
+
+			err := normalizeRecordData(props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+// =============================================================================
+// LOC, NAPTR and URI Tests
+// =============================================================================
+
+func TestNormalizeRecordData_LOCContent(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"51 30 12.748 N 0 7 39.611 W 0.00m 1m 10000m 10m", "51 30 12.748 N 0 7 39.611 W 0.00m 1.00m 10000.00m 10.00m"},
+		{"52 n 4 e 10", "52 0 0.000 N 4 0 0.000 E 10.00m 1.00m 10000.00m 10.00m"},
+		{"33 51 S 151 12 E -5.5m 20m", "33 51 0.000 S 151 12 0.000 E -5.50m 20.00m 10000.00m 10.00m"},
+		{"51 30 59.9996 N 0 59 59.9999 W 0m", "51 31 0.000 N 1 0 0.000 W 0.00m 1.00m 10000.00m 10.00m"},
+		{"89 59 59.9998 N 179 59 59.9995 E 0m", "90 0 0.000 N 180 0 0.000 E 0.00m 1.00m 10000.00m 10.00m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: "LOC", Name: "office", Content: tt.content}
+			if err := normalizeRecordData(props); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if props.Content != tt.expected {
+				t.Errorf("expected content %q, got %q", tt.expected, props.Content)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecordData_LOCData(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "LOC",
+		Name:       "office",
+		Data: json.RawMessage(`{
+			"lat_degrees": 37, "lat_minutes": 46, "lat_seconds": 46, "lat_direction": "N",
+			"long_degrees": 122, "long_minutes": 23, "long_seconds": 35, "long_direction": "W",
+			"altitude": 0, "size": 100, "precision_horz": 0, "precision_vert": 0
+		}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != "37 46 46.000 N 122 23 35.000 W 0.00m 100.00m 0.00m 0.00m" {
+		t.Errorf("unexpected content %q", props.Content)
+	}
+}
+
+func TestNormalizeRecordData_LOCDataDefaults(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "LOC",
+		Name:       "office",
+		Data: json.RawMessage(`{
+			"lat_degrees": 51, "lat_minutes": 30, "lat_seconds": 12.748, "lat_direction": "N",
+			"long_degrees": 0, "long_minutes": 7, "long_seconds": 39.611, "long_direction": "W"
+		}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != "51 30 12.748 N 0 7 39.611 W 0.00m 1.00m 10000.00m 10.00m" {
+		t.Errorf("expected RFC 1876 defaults in content, got %q", props.Content)
+	}
+
+	// The same record given as content must be equal
+	fromContent := &DNSRecordProperties{RecordType: "LOC", Name: "office", Content: "51 30 12.748 N 0 7 39.611 W 0m"}
+	if err := normalizeRecordData(fromContent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(fromContent.Data) != string(props.Data) {
+		t.Errorf("expected data %s, got %s", fromContent.Data, props.Data)
+	}
+}
+
+func TestNormalizeRecordData_NAPTR(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "NAPTR",
+		Name:       "@",
+		Content:    `100  10 "s" "SIP+D2U" "" _SIP._udp.example.com.`,
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != `100 10 "S" "SIP+D2U" "" _sip._udp.example.com` {
+		t.Errorf("unexpected content %q", props.Content)
+	}
+	expected := `{"order":100,"preference":10,"flags":"S","service":"SIP+D2U","regex":"","replacement":"_sip._udp.example.com"}`
+	if string(props.Data) != expected {
+		t.Errorf("expected data %s, got %s", expected, props.Data)
+	}
+
+	props = &DNSRecordProperties{
+		RecordType: "NAPTR",
+		Name:       "4.3.2.1.5.5.5.0.0.8.1.e164.arpa",
+		Data:       json.RawMessage(`{"order": 100, "preference": 10, "flags": "u", "service": "E2U+sip", "regex": "!^.*$!sip:info@example.com!", "replacement": "."}`),
+	}
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" .` {
+		t.Errorf("unexpected content %q", props.Content)
+	}
+}
+
+func TestNormalizeRecordData_URI(t *testing.T) {
+	props := &DNSRecordProperties{
+		RecordType: "URI",
+		Name:       "_ftp._tcp",
+		Data:       json.RawMessage(`{"priority": 10, "weight": 1, "target": "ftp://ftp.example.com/public"}`),
+	}
+
+	if err := normalizeRecordData(props); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if props.Content != `1 "ftp://ftp.example.com/public"` {
+		t.Errorf("unexpected content %q", props.Content)
+	}
+	if props.Priority == nil || *props.Priority != 10 {
+		t.Error("expected Priority 10 taken from data")
+	}
+}
+
+func TestNormalizeRecordData_LOCNAPTRURIInvalid(t *testing.T) {
+	tests := []struct {
+		recordType string
+		content    string
+		expected   string
+	}{
+		{"LOC", "91 0 0 N 0 0 0 E 0m", "lat_degrees must be between 0 and 90"},
+		{"LOC", "90 30 N 0 E 0m", "must not exceed 90 degrees latitude"},
+		{"LOC", "51 30 60 N 0 E 0m", "lat_seconds and long_seconds must be at least 0 and less than 60"},
+		{"LOC", "51 30 12.748 X 0 7 39.611 W 0m", "invalid latitude"},
+		{"LOC", "51 30 N 0 7 W", "expected \"altitude"},
+		{"LOC", "51 30 N 0 7 W 0m 1m 1m 1m 1m", "expected \"altitude"},
+		{"LOC", "51 30 N 0 7 W 0m 90000001m", "size must be between 0 and 90000000.00 meters"},
+		{"NAPTR", `100 10 "S" "SIP+D2U" ""`, "expected \"order preference flags service regex replacement\""},
+		{"NAPTR", `100 10 "S+" "SIP+D2U" "" _sip._udp.example.com`, "flags must consist of letters and digits"},
+		{"NAPTR", `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.com!" example.com`, "mutually exclusive"},
+		{"URI", `1 "ftp.example.com"`, "target must be an absolute URI"},
+		{"URI", `1`, "expected \"weight target\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType+" "+tt.expected, func(t *testing.T) {
+			props := &DNSRecordProperties{RecordType: tt.recordType, Name: "www", Content: tt.content, Priority: intPtr(1)}
+
+			err := normalizeRecordData(props)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// cloudflare-go's DNSRecordSettings only models flatten_cname, so records are
+// created, updated and read with raw requests that carry the full settings.
+// Everything else about the request and response is cloudflare-go's, including
+// error handling.
+
+// RecordSettings are Cloudflare's per-record settings.
+type RecordSettings struct {
+	FlattenCNAME bool `json:"flatten_cname,omitempty"` // unproxied CNAMEs only
+	IPv4Only     bool `json:"ipv4_only,omitempty"`     // proxied records only
+	IPv6Only     bool `json:"ipv6_only,omitempty"`     // proxied records only
+}
+
+// recordSettingsTypes are the record types each setting applies to.
+var recordSettingsTypes = map[string]map[string]bool{
+	"flatten_cname": {"CNAME": true},
+	"ipv4_only":     proxyableRecordTypes,
+	"ipv6_only":     proxyableRecordTypes,
+}
+
+// apiRecordSettings is the settings object sent to Cloudflare. Settings are
+// sent explicitly, including false values, so that updates clear settings
+// that are no longer declared.
+type apiRecordSettings struct {
+	FlattenCNAME *bool `json:"flatten_cname,omitempty"`
+	IPv4Only     *bool `json:"ipv4_only,omitempty"`
+	IPv6Only     *bool `json:"ipv6_only,omitempty"`
+}
+
+// dnsRecord is a Cloudflare DNS record with its full settings.
+type dnsRecord struct {
+	cloudflare.DNSRecord
+	Settings RecordSettings `json:"settings"`
+}
+
+// createRecordRequest and updateRecordRequest add the full settings to
+// cloudflare-go's record parameters.
+type createRecordRequest struct {
+	cloudflare.CreateDNSRecordParams
+	Settings *apiRecordSettings `json:"settings,omitempty"`
+}
+
+type updateRecordRequest struct {
+	cloudflare.UpdateDNSRecordParams
+	Settings *apiRecordSettings `json:"settings,omitempty"`
+}
+
+// validateSettings checks that settings apply to the record type and proxy
+// status, and drops settings that are all off.
+func validateSettings(props *DNSRecordProperties) error {
+	if props.Settings == nil {
+		return nil
+	}
+	s := props.Settings
+	enabled := map[string]bool{"flatten_cname": s.FlattenCNAME, "ipv4_only": s.IPv4Only, "ipv6_only": s.IPv6Only}
+	for _, name := range slices.Sorted(maps.Keys(enabled)) {
+		if enabled[name] && !recordSettingsTypes[name][props.RecordType] {
+			return fmt.Errorf("setting %s does not apply to %s records", name, props.RecordType)
+		}
+	}
+	switch {
+	case s.FlattenCNAME && props.Proxied:
+		return fmt.Errorf("setting flatten_cname only applies to records that are not proxied")
+	case (s.IPv4Only || s.IPv6Only) && !props.Proxied:
+		return fmt.Errorf("settings ipv4_only and ipv6_only only apply to proxied records")
+	case s.IPv4Only && s.IPv6Only:
+		return fmt.Errorf("settings ipv4_only and ipv6_only cannot both be set")
+	}
+	if *s == (RecordSettings{}) {
+		props.Settings = nil
+	}
+	return nil
+}
+
+// apiSettings returns the settings sent for a record: every setting that
+// applies to its type, or nil if there are none.
+func apiSettings(props *DNSRecordProperties) *apiRecordSettings {
+	var s RecordSettings
+	if props.Settings != nil {
+		s = *props.Settings
+	}
+
+	var settings apiRecordSettings
+	applies := false
+	if recordSettingsTypes["flatten_cname"][props.RecordType] {
+		settings.FlattenCNAME = &s.FlattenCNAME
+		applies = true
+	}
+	if recordSettingsTypes["ipv4_only"][props.RecordType] {
+		settings.IPv4Only = &s.IPv4Only
+		settings.IPv6Only = &s.IPv6Only
+		applies = true
+	}
+	if !applies {
+		return nil
+	}
+	return &settings
+}
+
+// createDNSRecord creates a record with its settings. A record with an
+// ownership marker may be sent again after a network error, as Create finds
+// the record if an earlier attempt created it.
+func createDNSRecord(ctx context.Context, client *cloudflare.API, zoneID string, props *DNSRecordProperties, zoneName string) (dnsRecord, error) {
+	body := createRecordRequest{
+		CreateDNSRecordParams: propsToCreateParams(props, zoneName),
+		Settings:              apiSettings(props),
+	}
+	if props.marker != "" {
+		ctx = withRetrySafe(ctx)
+	}
+	return rawRecordRequest(ctx, client, http.MethodPost, fmt.Sprintf("/zones/%s/dns_records", zoneID), body)
+}
+
+// updateDNSRecord updates a record and its settings.
+func updateDNSRecord(ctx context.Context, client *cloudflare.API, zoneID, recordID string, props *DNSRecordProperties, zoneName string) (dnsRecord, error) {
+	body := updateRecordRequest{
+		UpdateDNSRecordParams: propsToUpdateParams(props, recordID, zoneName),
+		Settings:              apiSettings(props),
+	}
+	return rawRecordRequest(ctx, client, http.MethodPatch, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, recordID), body)
+}
+
+// getDNSRecord reads a record with its settings.
+func getDNSRecord(ctx context.Context, client *cloudflare.API, zoneID, recordID string) (dnsRecord, error) {
+	return rawRecordRequest(ctx, client, http.MethodGet, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, recordID), nil)
+}
+
+func rawRecordRequest(ctx context.Context, client *cloudflare.API, method, endpoint string, body any) (dnsRecord, error) {
+	res, err := client.Raw(ctx, method, endpoint, body, nil)
+	if err != nil {
+		return dnsRecord{}, err
+	}
+	var record dnsRecord
+	if err := json.Unmarshal(res.Result, &record); err != nil {
+		return dnsRecord{}, fmt.Errorf("failed to parse DNS record: %w", err)
+	}
+	return record, nil
+}
+
+// =============================================================================
+// Existing Records
+// =============================================================================
+
+// errIdenticalRecord is returned when Create finds an identical record that
+// it does not adopt.
+var errIdenticalRecord = errors.New("an identical record already exists")
+
+// createOrAdoptDNSRecord creates a record. atName are the records at its
+// name, see listRecordsAt. A record carrying the ownership marker and content
+// of props was created by an earlier attempt and is updated to the desired
+// properties instead. If Cloudflare rejects the record because one with the
+// same type, name and content exists, that record is updated the same way if
+// it carries the marker or adopt is set, and reported with its ID otherwise.
+func createOrAdoptDNSRecord(ctx context.Context, client *cloudflare.API, zoneID string, props *DNSRecordProperties, zoneName string, adopt bool, atName []cloudflare.DNSRecord) (dnsRecord, error) {
+	if marked := findMarkedRecord(props, atName, zoneName); marked != nil {
+		return updateDNSRecord(ctx, client, zoneID, marked.ID, props, zoneName)
+	}
+
+	record, err := createDNSRecord(ctx, client, zoneID, props, zoneName)
+	if !isIdenticalRecordError(err) {
+		return record, err
+	}
+
+	// A request retried after a network error may have created the record
+	// since the records were listed, so they are listed again if it is not
+	// among them. Keep Cloudflare's error if the record cannot be found.
+	existing := findIdenticalRecord(props, atName, zoneName)
+	if existing == nil {
+		again, listErr := listRecordsAt(ctx, client, zoneID, props.Name, zoneName)
+		if listErr != nil {
+			return record, err
+		}
+		existing = findIdenticalRecord(props, again, zoneName)
+	}
+	if existing == nil {
+		return record, err
+	}
+	if _, marker := splitComment(existing.Comment); props.marker != "" && marker == props.marker {
+		return updateDNSRecord(ctx, client, zoneID, existing.ID, props, zoneName)
+	}
+	if !adopt {
+		return dnsRecord{}, fmt.Errorf("%w with ID %s; set adopt_existing to manage it", errIdenticalRecord, existing.ID)
+	}
+	return updateDNSRecord(ctx, client, zoneID, existing.ID, props, zoneName)
+}
+
+// listRecordsAt lists the records of all types at a canonical name. Create
+// and Update list them once and look for conflicts, marked and identical
+// records among them.
+func listRecordsAt(ctx context.Context, client *cloudflare.API, zoneID, name, zoneName string) ([]cloudflare.DNSRecord, error) {
+	records, _, err := client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(zoneID), cloudflare.ListDNSRecordsParams{
+		Name: recordFQDN(name, zoneName),
+	})
+	return records, err
+}
+
+// listRecordsBelow lists the records below a fully qualified name, using the
+// name.endswith filter cloudflare-go does not model.
+func listRecordsBelow(ctx context.Context, client *cloudflare.API, zoneID, fqdn string) ([]cloudflare.DNSRecord, error) {
+	var records []cloudflare.DNSRecord
+	for page := 1; ; page++ {
+		query := url.Values{
+			"name.endswith": {"." + fqdn},
+			"page":          {strconv.Itoa(page)},
+			"per_page":      {strconv.Itoa(listPageSize)},
+		}
+		res, err := client.Raw(ctx, http.MethodGet, fmt.Sprintf("/zones/%s/dns_records?%s", zoneID, query.Encode()), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result []cloudflare.DNSRecord
+		if err := json.Unmarshal(res.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse DNS records: %w", err)
+		}
+		records = append(records, result...)
+		if res.ResultInfo == nil || res.ResultInfo.Page >= res.ResultInfo.TotalPages {
+			return records, nil
+		}
+	}
+}
+
+// listPageSize is the page size of record lists made with raw requests.
+const listPageSize = 1000
+
+// findIdenticalRecord returns the record among records with the type, name
+// and content of props, or nil if there is none.
+func findIdenticalRecord(props *DNSRecordProperties, records []cloudflare.DNSRecord, zoneName string) *cloudflare.DNSRecord {
+	for _, record := range records {
+		if isSameRecord(record, props, zoneName) {
+			return &record
+		}
+	}
+	return nil
+}
+
+// isSameRecord reports whether a record has the type, canonical name and
+// content of props.
+func isSameRecord(record cloudflare.DNSRecord, props *DNSRecordProperties, zoneName string) bool {
+	return record.Type == props.RecordType && canonicalName(record.Name, zoneName) == props.Name && sameContent(record, props, zoneName)
+}
+
+// sameContent reports whether a record has the content of props, which must
+// be canonical. The record's content is brought into the same form first, as
+// Cloudflare may format it differently, e.g. by quoting TXT content, and Read
+// reports internationalized hostnames in Unicode rather than as A-labels.
+func sameContent(record cloudflare.DNSRecord, props *DNSRecordProperties, zoneName string) bool {
+	existing := recordToProperties(record, zoneName)
+	if err := validateContent(existing); err != nil {
+		return false
+	}
+	return existing.Content == props.Content
+}
+
+// adoptExisting reports whether Create adopts identical existing records. The
+// record option takes precedence over the target option.
+func adoptExisting(config *TargetConfig, props *DNSRecordProperties) bool {
+	if props.AdoptExisting != nil {
+		return *props.AdoptExisting
+	}
+	return config.AdoptExisting
+}
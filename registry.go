@@ -0,0 +1,280 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/platform-engineering-labs/formae/pkg/plugin/resource"
+)
+
+// With the TXT registry enabled (registry = "txt"), the plugin records which
+// formae installation owns the records of a type and name in a companion TXT
+// record, like external-dns does. The companion of the A records at
+// www.example.com is the TXT record _formae-owner.a.www.example.com with the
+// content "heritage=formae,formae/owner=<owner_id>". The plugin refuses to
+// create, update or delete records owned by another installation or by
+// external-dns, claims unowned records it writes, removes the companion with
+// the last record it owns, and reports the owner in Read.
+
+// registryTXT selects the TXT registry.
+const registryTXT = "txt"
+
+// Companion records of the TXT registry.
+const (
+	registryPrefix      = "_formae-owner"
+	formaeHeritage      = "formae"
+	externalDNSHeritage = "external-dns"
+	defaultOwnerID      = "default"
+)
+
+// errNotOwner is returned for records owned by someone else.
+var errNotOwner = errors.New("record is owned by someone else")
+
+// registry tracks record ownership in one zone. A nil registry (the default,
+// without registry = "txt") treats all records as unowned and records nothing.
+type registry struct {
+	client   *cloudflare.API
+	zoneID   string
+	zoneName string
+	owner    string
+}
+
+// registryEntry is the ownership of the records with one type and name.
+type registryEntry struct {
+	recordType string
+	name       string // canonical name
+
+	// owner is the owner ID for records owned by a formae installation,
+	// "external-dns/<owner>" for records owned by external-dns, or "" if
+	// the records are unowned.
+	owner string
+
+	// companion is the ownership record of this installation, if any.
+	companion *cloudflare.DNSRecord
+}
+
+// newRegistry returns the registry of a zone, or nil if the target does not
+// enable one.
+func newRegistry(config *TargetConfig, client *cloudflare.API, zoneID, zoneName string) *registry {
+	if config.Registry != registryTXT {
+		return nil
+	}
+	return &registry{client: client, zoneID: zoneID, zoneName: zoneName, owner: registryOwner(config)}
+}
+
+// zoneRegistry returns the registry of a zone for operations that have not
+// resolved the zone name yet, or nil if the target does not enable one.
+func (p *Plugin) zoneRegistry(ctx context.Context, client *cloudflare.API, config *TargetConfig, zone *recordZone) (*registry, error) {
+	if config.Registry != registryTXT {
+		return nil, nil
+	}
+	zoneName, err := p.resolveZoneName(ctx, client, config, zone)
+	if err != nil {
+		return nil, err
+	}
+	return newRegistry(config, client, zone.ID, zoneName), nil
+}
+
+// ownerRef returns the owner reported by Read, or nil if the records are
+// unowned or there is no registry.
+func (e *registryEntry) ownerRef() *string {
+	if e == nil || e.owner == "" {
+		return nil
+	}
+	return &e.owner
+}
+
+// registryOwner returns the owner ID the registry records.
+func registryOwner(config *TargetConfig) string {
+	if config.OwnerID == "" {
+		return defaultOwnerID
+	}
+	return config.OwnerID
+}
+
+// companionName returns the canonical name of the companion TXT record for
+// records with the given type and canonical name. A wildcard label becomes
+// "_wildcard", as "*" is only valid as the leftmost label.
+func companionName(recordType, name string) string {
+	prefix := registryPrefix + "." + strings.ToLower(recordType)
+	switch {
+	case name == apexName:
+		return prefix
+	case name == "*" || strings.HasPrefix(name, "*."):
+		name = "_wildcard" + name[1:]
+	}
+	return prefix + "." + name
+}
+
+// ownershipContent returns the content of a companion record.
+func ownershipContent(owner string) string {
+	return fmt.Sprintf("heritage=%s,%s/owner=%s", formaeHeritage, formaeHeritage, owner)
+}
+
+// parseOwnership returns the heritage and owner of registry content such as
+// "heritage=external-dns,external-dns/owner=default,external-dns/resource=...".
+func parseOwnership(content string) (heritage, owner string, ok bool) {
+	for _, field := range strings.Split(txtValue(content), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch {
+		case key == "heritage":
+			heritage = value
+		case strings.HasSuffix(key, "/owner"):
+			owner = value
+		}
+	}
+	return heritage, owner, heritage != ""
+}
+
+// isCompanionRecord reports whether a record is a companion record of the
+// TXT registry. Companions are not discovered.
+func isCompanionRecord(record cloudflare.DNSRecord) bool {
+	if record.Type != "TXT" {
+		return false
+	}
+	heritage, _, ok := parseOwnership(record.Content)
+	return ok && heritage == formaeHeritage
+}
+
+// lookup returns the ownership of the records with the given type and
+// canonical name. Ownership recorded by external-dns is found in its default
+// formats, a TXT record at the name itself or at "<type>-<name>".
+func (r *registry) lookup(ctx context.Context, recordType, name string) (*registryEntry, error) {
+	if r == nil {
+		return nil, nil
+	}
+	entry := &registryEntry{recordType: recordType, name: name}
+
+	companions, err := r.listTXT(ctx, companionName(recordType, name))
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range companions {
+		heritage, owner, ok := parseOwnership(record.Content)
+		if !ok || heritage != formaeHeritage {
+			continue
+		}
+		entry.owner = owner
+		if owner == r.owner {
+			entry.companion = &record
+			return entry, nil
+		}
+	}
+	if entry.owner != "" {
+		return entry, nil
+	}
+
+	externalNames := []string{name}
+	if name != apexName && !strings.HasPrefix(name, "*") {
+		externalNames = append(externalNames, strings.ToLower(recordType)+"-"+name)
+	}
+	for _, externalName := range externalNames {
+		records, err := r.listTXT(ctx, externalName)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if heritage, owner, ok := parseOwnership(record.Content); ok && heritage == externalDNSHeritage {
+				entry.owner = externalDNSHeritage + "/" + owner
+				return entry, nil
+			}
+		}
+	}
+	return entry, nil
+}
+
+// check returns the ownership of the records with the given type and
+// canonical name, or errNotOwner if someone else owns them.
+func (r *registry) check(ctx context.Context, recordType, name string) (*registryEntry, error) {
+	entry, err := r.lookup(ctx, recordType, name)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+	if entry.owner != "" && entry.companion == nil {
+		return nil, fmt.Errorf("%w: %s records at %s are owned by %q", errNotOwner, recordType, name, entry.owner)
+	}
+	return entry, nil
+}
+
+// checkRecord returns the ownership of an existing record, or errNotOwner if
+// someone else owns it.
+func (r *registry) checkRecord(ctx context.Context, recordID string) (*registryEntry, error) {
+	if r == nil {
+		return nil, nil
+	}
+	record, err := getDNSRecord(ctx, r.client, r.zoneID, recordID)
+	if err != nil {
+		return nil, err
+	}
+	return r.check(ctx, record.Type, canonicalName(record.Name, r.zoneName))
+}
+
+// claim records this installation as the owner of unowned records.
+func (r *registry) claim(ctx context.Context, entry *registryEntry) error {
+	if r == nil || entry == nil || entry.companion != nil {
+		return nil
+	}
+	props := &DNSRecordProperties{
+		RecordType: "TXT",
+		Name:       companionName(entry.recordType, entry.name),
+		Content:    ownershipContent(r.owner),
+		TTL:        automaticTTL,
+	}
+	// A retried request that finds the record it created is handled like a
+	// claim made meanwhile
+	record, err := createDNSRecord(withRetrySafe(ctx), r.client, r.zoneID, props, r.zoneName)
+	if isIdenticalRecordError(err) {
+		// Claimed meanwhile by another operation of this installation
+		current, err := r.lookup(ctx, entry.recordType, entry.name)
+		if err != nil {
+			return fmt.Errorf("failed to record ownership: %w", err)
+		}
+		*entry = *current
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record ownership: %w", err)
+	}
+	entry.owner = r.owner
+	entry.companion = &record.DNSRecord
+	return nil
+}
+
+// release removes the ownership record once no records of its type and name
+// are left.
+func (r *registry) release(ctx context.Context, entry *registryEntry) error {
+	if r == nil || entry == nil || entry.companion == nil {
+		return nil
+	}
+	records, _, err := r.client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(r.zoneID), cloudflare.ListDNSRecordsParams{
+		Type: entry.recordType,
+		Name: recordFQDN(entry.name, r.zoneName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release ownership: %w", err)
+	}
+	if len(records) > 0 {
+		return nil
+	}
+	err = r.client.DeleteDNSRecord(ctx, cloudflare.ZoneIdentifier(r.zoneID), entry.companion.ID)
+	if err != nil && errorCode(err) != resource.OperationErrorCodeNotFound {
+		return fmt.Errorf("failed to release ownership: %w", err)
+	}
+	return nil
+}
+
+// listTXT returns the TXT records with the given canonical name.
+func (r *registry) listTXT(ctx context.Context, name string) ([]cloudflare.DNSRecord, error) {
+	records, _, err := r.client.ListDNSRecords(ctx, cloudflare.ZoneIdentifier(r.zoneID), cloudflare.ListDNSRecordsParams{
+		Type: "TXT",
+		Name: recordFQDN(name, r.zoneName),
+	})
+	return records, err
+}
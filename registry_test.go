@@ -0,0 +1,79 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+func TestCompanionName(t *testing.T) {
+	tests := []struct {
+		recordType string
+		name       string
+		expected   string
+	}{
+		{"A", "www", "_formae-owner.a.www"},
+		{"CNAME", "api.eu", "_formae-owner.cname.api.eu"},
+		{"MX", "@", "_formae-owner.mx"},
+		{"A", "*", "_formae-owner.a._wildcard"},
+		{"A", "*.dev", "_formae-owner.a._wildcard.dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType+" "+tt.name, func(t *testing.T) {
+			if got := companionName(tt.recordType, tt.name); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseOwnership(t *testing.T) {
+	tests := []struct {
+		content  string
+		heritage string
+		owner    string
+		ok       bool
+	}{
+		{ownershipContent("prod"), "formae", "prod", true},
+		{`"heritage=formae,formae/owner=prod"`, "formae", "prod", true},
+		{"heritage=external-dns,external-dns/owner=default,external-dns/resource=ingress/web/web", "external-dns", "default", true},
+		{"v=spf1 -all", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			heritage, owner, ok := parseOwnership(tt.content)
+			if heritage != tt.heritage || owner != tt.owner || ok != tt.ok {
+				t.Errorf("expected (%q, %q, %v), got (%q, %q, %v)", tt.heritage, tt.owner, tt.ok, heritage, owner, ok)
+			}
+		})
+	}
+}
+
+func TestIsCompanionRecord(t *testing.T) {
+	if !isCompanionRecord(cloudflare.DNSRecord{Type: "TXT", Content: `"heritage=formae,formae/owner=prod"`}) {
+		t.Error("expected a formae ownership record to be a companion")
+	}
+	if isCompanionRecord(cloudflare.DNSRecord{Type: "TXT", Content: "heritage=external-dns,external-dns/owner=default"}) {
+		t.Error("expected external-dns ownership records to be discoverable")
+	}
+	if isCompanionRecord(cloudflare.DNSRecord{Type: "TXT", Content: "v=spf1 -all"}) {
+		t.Error("expected a plain TXT record not to be a companion")
+	}
+}
+
+func TestParseTargetConfig_Registry(t *testing.T) {
+	if _, err := parseTargetConfig([]byte(`{"api_token": "token", "zone_id": "zone", "registry": "txt"}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := parseTargetConfig([]byte(`{"api_token": "token", "zone_id": "zone", "registry": "dynamodb"}`)); err == nil {
+		t.Error("expected error for unknown registry, got nil")
+	}
+}
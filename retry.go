@@ -0,0 +1,240 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retry defaults, used when the target config does not override them.
+const (
+	defaultRetryMaxAttempts = 4
+	defaultRetryMaxDelay    = 30 * time.Second
+	retryBaseDelay          = 500 * time.Millisecond
+)
+
+// retryTransport retries Cloudflare API requests that failed with 429, a 5xx
+// or a network error. Retry-After is honored; otherwise it backs off
+// exponentially with jitter. It never waits past the request's context
+// deadline: if the next attempt cannot start in time, the last response is
+// returned as-is. POST requests are not idempotent: after a network error
+// that may have come after Cloudflare processed them, such as a response
+// timeout, they are only retried if marked with withRetrySafe.
+type retryTransport struct {
+	next http.RoundTripper
+
+	maxAttempts int           // total attempts, including the first
+	maxDelay    time.Duration // upper bound for a single wait between attempts
+	baseDelay   time.Duration // first backoff delay, doubled on every attempt
+	timeout     time.Duration // per-attempt timeout; zero means none
+}
+
+// newRetryTransport wraps next with the retry settings of the target config.
+func newRetryTransport(next http.RoundTripper, config *TargetConfig) *retryTransport {
+	t := &retryTransport{
+		next:        next,
+		maxAttempts: defaultRetryMaxAttempts,
+		maxDelay:    defaultRetryMaxDelay,
+		baseDelay:   retryBaseDelay,
+		timeout:     time.Duration(config.RequestTimeoutSeconds) * time.Second,
+	}
+	if config.RetryMaxAttempts > 0 {
+		t.maxAttempts = config.RetryMaxAttempts
+	}
+	if config.RetryMaxDelaySeconds > 0 {
+		t.maxDelay = time.Duration(config.RetryMaxDelaySeconds) * time.Second
+	}
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := t.rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.roundTrip(attemptReq)
+		if ctx.Err() != nil || !shouldRetry(req, resp, err) || attempt >= t.maxAttempts || !rewindable(req) {
+			return resp, err
+		}
+
+		delay, ok := t.delay(resp, attempt)
+		if !ok || !fitsDeadline(ctx, delay) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain so the connection can be reused.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// rewind returns the request to send for the given attempt, with a fresh body
+// for retries.
+func (t *retryTransport) rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, nil
+}
+
+// rewindable reports whether the request body can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// roundTrip sends a single attempt, bounded by the per-attempt timeout.
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// delay returns how long to wait before the next attempt. It returns false if
+// the server asked to wait longer than the configured maximum.
+func (t *retryTransport) delay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return retryAfter, retryAfter <= t.maxDelay
+		}
+	}
+
+	backoff := t.baseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > t.maxDelay {
+		backoff = t.maxDelay
+	}
+	// Jitter in [backoff/2, backoff] so concurrent operations spread out.
+	half := backoff / 2
+	return half + rand.N(half+1), true
+}
+
+// shouldRetry reports whether a failed attempt is worth retrying.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return isTransientNetworkError(err) && (isRetrySafe(req) || isDialError(err))
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// isTransientNetworkError reports whether a transport error may go away on
+// retry. Cancellation, the rate limiter holding a request past its deadline
+// and certificate problems will not; a per-attempt timeout may.
+func isTransientNetworkError(err error) bool {
+	var throttledErr *throttledError
+	if errors.Is(err, context.Canceled) || errors.As(err, &throttledErr) {
+		return false
+	}
+
+	var verifyErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return !errors.As(err, &verifyErr) &&
+		!errors.As(err, &unknownAuthorityErr) &&
+		!errors.As(err, &hostnameErr) &&
+		!errors.As(err, &invalidErr)
+}
+
+// retrySafeKey is the context key set by withRetrySafe.
+type retrySafeKey struct{}
+
+// withRetrySafe marks the requests made with ctx as safe to send again even
+// if an earlier attempt may have been processed, e.g. a POST creating a
+// record that the caller finds again if it already exists.
+func withRetrySafe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retrySafeKey{}, true)
+}
+
+// isRetrySafe reports whether a request may be sent again after a network
+// error. Requests other than POST are idempotent.
+func isRetrySafe(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return true
+	}
+	safe, _ := req.Context().Value(retrySafeKey{}).(bool)
+	return safe
+}
+
+// isDialError reports whether a request failed before it was sent because no
+// connection could be made.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// fitsDeadline reports whether waiting delay still leaves time before the
+// context deadline.
+func fitsDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// cancelOnClose releases a per-attempt context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,219 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/platform-engineering-labs/formae/pkg/plugin/resource"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"7", 7 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q): expected %s (%v), got %s (%v)", tt.value, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestRetryTransport_BackoffBounds(t *testing.T) {
+	rt := &retryTransport{baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		expected := min(rt.baseDelay<<(attempt-1), rt.maxDelay)
+		for i := 0; i < 20; i++ {
+			delay, ok := rt.delay(nil, attempt)
+			if !ok {
+				t.Fatalf("attempt %d: expected a delay", attempt)
+			}
+			if delay < expected/2 || delay > expected {
+				t.Errorf("attempt %d: expected delay in [%s, %s], got %s", attempt, expected/2, expected, delay)
+			}
+		}
+	}
+}
+
+func TestRetryTransport_RetriesWithBody(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"www"}` {
+			t.Errorf("expected the request body on every attempt, got %q", body)
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		next:        http.DefaultTransport,
+		maxAttempts: 3,
+		maxDelay:    10 * time.Millisecond,
+		baseDelay:   time.Millisecond,
+	}}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"name":"www"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryTransport_StopsAtMaxAttempts(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		next:        http.DefaultTransport,
+		maxAttempts: 2,
+		maxDelay:    10 * time.Millisecond,
+		baseDelay:   time.Millisecond,
+	}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the last 503 to be returned, got %d", resp.StatusCode)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestRetryTransport_DoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{next: http.DefaultTransport, maxAttempts: 3, baseDelay: time.Millisecond}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+}
+
+func TestRetryTransport_DoesNotRetryThrottledRequests(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+	}))
+	defer server.Close()
+
+	limiter := newTokenLimiter(10)
+	limiter.observe(rateLimitResponse(0, 1200, 30), time.Now())
+	limited := &rateLimitTransport{next: http.DefaultTransport, limiter: limiter}
+	client := &http.Client{Transport: &retryTransport{next: limited, maxAttempts: 3, maxDelay: time.Millisecond, baseDelay: time.Millisecond}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	_, err := client.Do(req)
+	if errorCode(err) != resource.OperationErrorCodeThrottling {
+		t.Errorf("expected Throttling, got %s (%v)", errorCode(err), err)
+	}
+	if n := attempts.Load(); n != 0 {
+		t.Errorf("expected no attempts, got %d", n)
+	}
+	if ctx.Err() != nil {
+		t.Error("expected the request to fail before its deadline")
+	}
+}
+
+func TestRetryTransport_RetriesPOSTAfterTimeoutOnlyIfSafe(t *testing.T) {
+	tests := []struct {
+		name     string
+		safe     bool
+		attempts int32
+	}{
+		{"unmarked", false, 1},
+		{"retry safe", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				time.Sleep(100 * time.Millisecond)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{
+				next:        http.DefaultTransport,
+				maxAttempts: 2,
+				maxDelay:    time.Millisecond,
+				baseDelay:   time.Millisecond,
+				timeout:     20 * time.Millisecond,
+			}}
+
+			ctx := context.Background()
+			if tt.safe {
+				ctx = withRetrySafe(ctx)
+			}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"name":"www"}`))
+
+			if _, err := client.Do(req); err == nil {
+				t.Fatal("expected a timeout error, got nil")
+			}
+			if n := attempts.Load(); n != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTXTStringLen is the longest character-string a TXT record can hold
+// (RFC 1035, section 3.3). Longer values are split into several strings that
+// resolvers concatenate.
+const maxTXTStringLen = 255
+
+// TXT values are declared and reported as the plain value resolvers see after
+// concatenating the record's character-strings, e.g. a DKIM key as one long
+// string. Content already written as quoted character-strings
+// ("v=DKIM1; k=rsa; " "p=MIIB...") is accepted and joined. Cloudflare always
+// receives the value as quoted strings of at most 255 bytes.
+
+// txtValue returns the plain value of TXT content. Content consisting of
+// quoted character-strings is unquoted and joined; any other content is
+// returned unchanged.
+func txtValue(content string) string {
+	strs, ok := parseTXTStrings(content)
+	if !ok {
+		return content
+	}
+	return strings.Join(strs, "")
+}
+
+// txtContent returns a TXT value as quoted character-strings of at most 255
+// bytes, as sent to Cloudflare. Strings are not split inside a UTF-8 sequence.
+func txtContent(value string) string {
+	var strs []string
+	for {
+		n := len(value)
+		if n > maxTXTStringLen {
+			n = maxTXTStringLen
+			for n > 0 && !utf8.RuneStart(value[n]) {
+				n--
+			}
+		}
+		strs = append(strs, quoteTXTString(value[:n]))
+		value = value[n:]
+		if value == "" {
+			return strings.Join(strs, " ")
+		}
+	}
+}
+
+// quoteTXTString quotes a character-string, escaping quotes and backslashes.
+func quoteTXTString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// parseTXTStrings splits content into its quoted character-strings. It
+// reports false unless the whole content consists of quoted strings separated
+// by whitespace. Escapes are "\X" for a literal X and "\DDD" for a decimal
+// byte value.
+func parseTXTStrings(content string) ([]string, bool) {
+	s := strings.TrimSpace(content)
+	if !strings.HasPrefix(s, `"`) {
+		return nil, false
+	}
+
+	var strs []string
+	for s != "" {
+		if s[0] != '"' {
+			return nil, false
+		}
+		str, rest, err := unquoteTXTString(s)
+		if err != nil {
+			return nil, false
+		}
+		strs = append(strs, str)
+		trimmed := strings.TrimLeft(rest, " \t")
+		if trimmed != "" && len(trimmed) == len(rest) {
+			// Strings must be separated by whitespace
+			return nil, false
+		}
+		s = trimmed
+	}
+	return strs, true
+}
+
+// unquoteTXTString decodes the quoted string at the start of s and returns it
+// with the remainder of s.
+func unquoteTXTString(s string) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+3 < len(s) && isDigits(s[i+1:i+4]) {
+				v, _ := strconv.Atoi(s[i+1 : i+4])
+				if v > 255 {
+					return "", "", fmt.Errorf("invalid escape \\%s", s[i+1:i+4])
+				}
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+			if i+1 == len(s) {
+				return "", "", fmt.Errorf("unterminated escape")
+			}
+			i++
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote")
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
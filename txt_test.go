@@ -0,0 +1,73 @@
+//go:build unit
+
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTXTValue(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"v=spf1 -all", "v=spf1 -all"},
+		{`"v=spf1 -all"`, "v=spf1 -all"},
+		{`"v=DKIM1; k=rsa; " "p=MIIB"`, "v=DKIM1; k=rsa; p=MIIB"},
+		{`"say \"hi\"" "C:\\"`, `say "hi"C:\`},
+		{`"caf\195\169"`, "café"},
+		{`"unterminated`, `"unterminated`},
+		{`"a" b`, `"a" b`},
+		{`"a""b"`, `"a""b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			if got := txtValue(tt.content); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTXTContent_Chunks(t *testing.T) {
+	value := strings.Repeat("a", 300)
+
+	content := txtContent(value)
+	expected := `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`
+	if content != expected {
+		t.Errorf("expected two strings of 255 and 45 bytes, got %q", content)
+	}
+	if got := txtValue(content); got != value {
+		t.Errorf("expected value to round-trip, got %q", got)
+	}
+}
+
+func TestTXTContent_KeepsRunesWhole(t *testing.T) {
+	value := strings.Repeat("a", 254) + "é"
+
+	strs, ok := parseTXTStrings(txtContent(value))
+	if !ok {
+		t.Fatal("expected quoted strings")
+	}
+	if len(strs) != 2 || strs[0] != strings.Repeat("a", 254) || strs[1] != "é" {
+		t.Errorf("expected the rune to move to the second string, got %q", strs)
+	}
+}
+
+func TestTXTContent_EscapesQuotes(t *testing.T) {
+	value := `say "hi" \o/`
+
+	content := txtContent(value)
+	if content != `"say \"hi\" \\o/"` {
+		t.Errorf("expected escaped content, got %q", content)
+	}
+	if got := txtValue(content); got != value {
+		t.Errorf("expected value to round-trip, got %q", got)
+	}
+}
@@ -0,0 +1,179 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits enforced before records are sent to Cloudflare.
+const (
+	automaticTTL   = 1     // lets Cloudflare choose the TTL
+	minTTL         = 30    // shortest explicit TTL
+	maxTTL         = 86400 // longest TTL Cloudflare accepts
+	maxPriority    = 65535
+	maxTXTLength   = 2048 // Cloudflare's limit for quoted TXT content
+	maxHostnameLen = 253
+	maxLabelLen    = 63
+	maxCommentLen  = 100 // Cloudflare's limit on the Free plan, marker included
+)
+
+// hostnameRecordTypes are the record types whose content is a hostname.
+var hostnameRecordTypes = map[string]bool{
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"PTR":   true,
+}
+
+// validateContent checks the content of record types without a structured
+// form and brings it into canonical form. Structured types are validated by
+// normalizeRecordData.
+func validateContent(props *DNSRecordProperties) error {
+	switch {
+	case props.RecordType == "A":
+		ip := net.ParseIP(props.Content)
+		if ip == nil || ip.To4() == nil || strings.Contains(props.Content, ":") {
+			return fmt.Errorf("invalid A record content %q: must be an IPv4 address", props.Content)
+		}
+		props.Content = ip.String()
+	case props.RecordType == "AAAA":
+		ip := net.ParseIP(props.Content)
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("invalid AAAA record content %q: must be an IPv6 address", props.Content)
+		}
+		props.Content = ip.String()
+	case props.RecordType == "TXT":
+		props.Content = txtValue(props.Content)
+		if n := len(txtContent(props.Content)); n > maxTXTLength {
+			return fmt.Errorf("invalid TXT record content: %d characters exceeds the limit of %d once quoted", n, maxTXTLength)
+		}
+	case props.RecordType == "MX" && props.Content == ".":
+		// Null MX (RFC 7505): the domain accepts no mail.
+	case hostnameRecordTypes[props.RecordType] && props.Content == apexName:
+		// The zone name, filled in by setCanonicalName once it is known.
+	case hostnameRecordTypes[props.RecordType]:
+		// Internationalized hostnames are sent as A-labels, and like names
+		// without a trailing dot, as Cloudflare reports them.
+		hostname, err := asciiName(props.Content)
+		if err != nil {
+			return fmt.Errorf("invalid %s record content %q: %w", props.RecordType, props.Content, err)
+		}
+		// CNAME targets often point at underscore names, e.g. for ACME
+		// challenge delegation.
+		if err := validateHostname(hostname, props.RecordType == "CNAME"); err != nil {
+			return fmt.Errorf("invalid %s record content %q: %w", props.RecordType, props.Content, err)
+		}
+		props.Content = strings.TrimSuffix(hostname, ".")
+	}
+	return nil
+}
+
+// validateHostname checks that name is a hostname as defined by RFC 1123,
+// optionally fully qualified with a trailing dot. If allowUnderscore is set,
+// labels may also contain underscores.
+func validateHostname(name string, allowUnderscore bool) error {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" {
+		return fmt.Errorf("must be a hostname")
+	}
+	if len(name) > maxHostnameLen {
+		return fmt.Errorf("hostname exceeds %d characters", maxHostnameLen)
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return fmt.Errorf("hostname must not contain empty labels")
+		}
+		if len(label) > maxLabelLen {
+			return fmt.Errorf("label %q exceeds %d characters", label, maxLabelLen)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("label %q must not start or end with a hyphen", label)
+		}
+		for _, r := range label {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			case r == '_' && allowUnderscore:
+			default:
+				return fmt.Errorf("label %q contains invalid character %q", label, r)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRecordName checks that a canonical record name is the apex or a
+// hostname whose labels may contain underscores (e.g. "_sip._tcp"), with an
+// optional leading wildcard label.
+func validateRecordName(name string) error {
+	if name == apexName || name == "*" {
+		return nil
+	}
+	ascii, err := asciiName(name)
+	if err != nil {
+		return err
+	}
+	return validateHostname(strings.TrimPrefix(ascii, "*."), true)
+}
+
+// validateTTL checks that ttl is automatic (1) or within Cloudflare's range.
+func validateTTL(ttl int) error {
+	if ttl != automaticTTL && (ttl < minTTL || ttl > maxTTL) {
+		return fmt.Errorf("ttl must be 1 (automatic) or between %d and %d, got %d", minTTL, maxTTL, ttl)
+	}
+	return nil
+}
+
+// validatePriority checks that a priority fits into 16 bits.
+func validatePriority(priority *int) error {
+	if priority != nil && (*priority < 0 || *priority > maxPriority) {
+		return fmt.Errorf("priority must be between 0 and %d, got %d", maxPriority, *priority)
+	}
+	return nil
+}
+
+// validateComment checks that a comment fits Cloudflare's limit together
+// with the ownership marker appended to it.
+func validateComment(comment *string) error {
+	if comment == nil {
+		return nil
+	}
+	if length := utf8.RuneCountInString(*comment); length > maxCommentLen-markerLen {
+		return fmt.Errorf("comment must be at most %d characters, as the ownership marker takes %d of the %d Cloudflare allows, got %d", maxCommentLen-markerLen, markerLen, maxCommentLen, length)
+	}
+	return nil
+}
+
+// validateTags checks that tag keys are set, unique and free of the ":"
+// separator Cloudflare uses between key and value, and sorts tags by key.
+func validateTags(tags []DNSRecordTag) error {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag.Key) == "" {
+			return fmt.Errorf("tag key must not be empty")
+		}
+		if strings.Contains(tag.Key, ":") {
+			return fmt.Errorf("tag key %q must not contain ':'", tag.Key)
+		}
+	}
+	sortTags(tags)
+	for i := 1; i < len(tags); i++ {
+		if tags[i].Key == tags[i-1].Key {
+			return fmt.Errorf("duplicate tag key %q", tags[i].Key)
+		}
+	}
+	return nil
+}
+
+// sortTags sorts tags by key, the order in which they are reported.
+func sortTags(tags []DNSRecordTag) {
+	slices.SortFunc(tags, func(a, b DNSRecordTag) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+}
@@ -0,0 +1,222 @@
+// © 2025 Platform Engineering Labs Inc.
+//
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// errZoneNotResolved is returned when a zone name does not match exactly one zone.
+var errZoneNotResolved = errors.New("zone could not be resolved")
+
+// errZoneNotPermitted is returned when a record selects a zone the target does not allow.
+var errZoneNotPermitted = errors.New("zone is not permitted by the target")
+
+// recordZone is the zone a DNS record lives in.
+type recordZone struct {
+	// Ref is the zone reference (name or ID) embedded in the record's native ID.
+	// Empty for records in the default zone of a single-zone target.
+	Ref string
+
+	ID string
+
+	// Name is the zone name if it is known without an API call, otherwise empty.
+	Name string
+}
+
+// =============================================================================
+// Native IDs
+// =============================================================================
+
+// Records whose zone was selected explicitly (via the record's zone property or
+// a multi-zone target) use "<zone>/<record id>" as native ID, so Read, Update
+// and Delete can find the zone without the record's properties. Records in
+// the default zone of a single-zone target use the plain record ID.
+
+// joinNativeID builds the native ID for a record in the given zone.
+func joinNativeID(zoneRef, recordID string) string {
+	if zoneRef == "" {
+		return recordID
+	}
+	return zoneRef + "/" + recordID
+}
+
+// splitNativeID splits a native ID into its zone reference and record ID.
+func splitNativeID(nativeID string) (zoneRef, recordID string) {
+	if i := strings.LastIndex(nativeID, "/"); i >= 0 {
+		return nativeID[:i], nativeID[i+1:]
+	}
+	return "", nativeID
+}
+
+// =============================================================================
+// Zone References
+// =============================================================================
+
+// normalizeZoneName lowercases a zone name and strips any trailing dot.
+func normalizeZoneName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// isZoneName reports whether a zone reference is a zone name rather than a
+// zone ID. Zone IDs are opaque hex strings and never contain a dot.
+func isZoneName(ref string) bool {
+	return strings.Contains(ref, ".")
+}
+
+// normalizeZoneRef normalizes a zone reference given by the user.
+func normalizeZoneRef(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if isZoneName(ref) {
+		return normalizeZoneName(ref)
+	}
+	return ref
+}
+
+// isMultiZone reports whether the target manages records in several zones.
+func (c *TargetConfig) isMultiZone() bool {
+	return len(c.Zones) > 0
+}
+
+// allowedZones returns the zone references records of this target may live in.
+func (c *TargetConfig) allowedZones() []string {
+	if c.isMultiZone() {
+		return c.Zones
+	}
+	if c.ZoneID != "" {
+		return []string{c.ZoneID}
+	}
+	return []string{c.ZoneName}
+}
+
+// =============================================================================
+// Zone Resolution
+// =============================================================================
+
+// resolveRecordZone resolves the zone a record lives in from its zone
+// reference. An empty reference selects the default zone of a single-zone
+// target. References outside the target's allowed zones are rejected.
+func (p *Plugin) resolveRecordZone(ctx context.Context, client *cloudflare.API, config *TargetConfig, ref string) (*recordZone, error) {
+	if ref == "" {
+		if config.isMultiZone() {
+			return nil, fmt.Errorf("%w: zone is required for targets with multiple zones", errZoneNotPermitted)
+		}
+		zoneID, err := p.resolveZoneID(ctx, client, config, normalizeZoneRef(config.allowedZones()[0]))
+		if err != nil {
+			return nil, err
+		}
+		return &recordZone{ID: zoneID, Name: normalizeZoneName(config.ZoneName)}, nil
+	}
+
+	ref = normalizeZoneRef(ref)
+	zoneID, err := p.resolveZoneID(ctx, client, config, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	permitted, err := p.zonePermitted(ctx, client, config, ref, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	if !permitted {
+		return nil, fmt.Errorf("%w: %q is not one of %s", errZoneNotPermitted, ref, strings.Join(config.allowedZones(), ", "))
+	}
+
+	zone := &recordZone{Ref: ref, ID: zoneID}
+	if isZoneName(ref) {
+		zone.Name = ref
+	}
+	return zone, nil
+}
+
+// zonePermitted reports whether the zone identified by ref and zoneID is one of
+// the target's allowed zones.
+func (p *Plugin) zonePermitted(ctx context.Context, client *cloudflare.API, config *TargetConfig, ref, zoneID string) (bool, error) {
+	for _, allowed := range config.allowedZones() {
+		allowed = normalizeZoneRef(allowed)
+		if allowed == ref {
+			return true, nil
+		}
+		allowedID, err := p.resolveZoneID(ctx, client, config, allowed)
+		if err != nil {
+			return false, err
+		}
+		if allowedID == zoneID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveZoneID returns the zone ID for a zone reference, looking zone names
+// up via the zones API.
+func (p *Plugin) resolveZoneID(ctx context.Context, client *cloudflare.API, config *TargetConfig, ref string) (string, error) {
+	if !isZoneName(ref) {
+		return ref, nil
+	}
+
+	zoneName := normalizeZoneName(ref)
+	if zoneID, ok := p.cache.zoneID(config, zoneName); ok {
+		return zoneID, nil
+	}
+
+	zones, err := client.ListZonesContext(ctx, cloudflare.WithZoneFilters(zoneName, config.AccountID, ""))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up zone %q: %w", zoneName, err)
+	}
+
+	var zoneID string
+	switch len(zones.Result) {
+	case 0:
+		return "", fmt.Errorf("%w: no zone named %q is accessible with this API token", errZoneNotResolved, zoneName)
+	case 1:
+		zoneID = zones.Result[0].ID
+	default:
+		ids := make([]string, 0, len(zones.Result))
+		for _, zone := range zones.Result {
+			ids = append(ids, zone.ID)
+		}
+		return "", fmt.Errorf("%w: zone name %q is ambiguous, matching zones %s; use the zone ID or set account_id",
+			errZoneNotResolved, zoneName, strings.Join(ids, ", "))
+	}
+
+	p.cache.setZoneID(config, zoneName, zoneID)
+	p.cache.setZoneName(config, zoneID, zoneName)
+
+	return zoneID, nil
+}
+
+// resolveZoneName returns the name of a resolved zone, fetching it from
+// Cloudflare if it is neither known nor cached.
+func (p *Plugin) resolveZoneName(ctx context.Context, client *cloudflare.API, config *TargetConfig, zone *recordZone) (string, error) {
+	if zone.Name != "" {
+		return zone.Name, nil
+	}
+	if zoneName, ok := p.cache.zoneName(config, zone.ID); ok {
+		return zoneName, nil
+	}
+
+	zoneName, err := getZoneName(ctx, client, zone.ID)
+	if err != nil {
+		return "", err
+	}
+	p.cache.setZoneName(config, zone.ID, zoneName)
+
+	return zoneName, nil
+}
+
+// getZoneName fetches the zone name from Cloudflare using the zone ID.
+func getZoneName(ctx context.Context, client *cloudflare.API, zoneID string) (string, error) {
+	zone, err := client.ZoneDetails(ctx, zoneID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get zone details: %w", err)
+	}
+	return zone.Name, nil
+}